@@ -14,4 +14,22 @@ const (
 	ErrInvalidPresetInput          = "invalid input"
 	ErrStoreNotFound               = "store not found"
 	ErrBadInput                    = "bad Request"
+	ErrPresetQuotaExceeded         = "preset quota exceeded"
+	ErrInvalidItemInput            = "invalid item input"
+	ErrInvalidPresetExport         = "invalid preset export"
+	ErrUnsupportedPresetExport     = "unsupported preset export version"
+	ErrNotMyFolder                 = "not my folder"
+	ErrInvalidFolderInput          = "invalid folder input"
+	ErrFolderClassMismatch         = "preset class does not match folder"
+	ErrFolderLimitExceeded         = "folder limit exceeded"
+	ErrPresetAlreadyPublished      = "preset is already published"
+	ErrPresetNotPublished          = "preset is not published"
+	ErrPresetVersionRequired       = "preset version is required"
+	ErrPresetVersionConflict       = "preset was changed since this version"
+	ErrInvalidPresetCursor         = "invalid cursor"
+	ErrInvalidTagInput             = "invalid tag input"
+	ErrTagBanned                   = "tag is banned"
+	ErrTagAlreadyExists            = "tag already exists"
+	ErrInvalidReportInput          = "invalid report input"
+	ErrReportAlreadyOpen           = "already reported"
 )
@@ -0,0 +1,15 @@
+package appError
+
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// ValidationError keeps ErrInvalidPresetInput as its message, so it maps like the plain error when only the message is used.
+type ValidationError struct {
+	Errors []FieldError
+}
+
+func (e *ValidationError) Error() string {
+	return ErrInvalidPresetInput
+}
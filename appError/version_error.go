@@ -0,0 +1,10 @@
+package appError
+
+// VersionConflictError keeps ErrPresetVersionConflict as its message and carries the version the preset is at now.
+type VersionConflictError struct {
+	CurrentVersion int
+}
+
+func (e *VersionConflictError) Error() string {
+	return ErrPresetVersionConflict
+}
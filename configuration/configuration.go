@@ -30,7 +30,15 @@ type JwtConfig struct {
 }
 
 type RoConfig struct {
-	PresetLimit int
+	// limits <= 0 are unlimited
+	PresetLimit      int
+	AdminPresetLimit int
+	// overspent presets are only flagged unless this is set, then saving and publishing reject them
+	RejectStatOverspent bool
+	// deleted presets are purged after this many days, <= 0 uses 30
+	TrashRetentionDays int
+	// trending scores are computed again after this many minutes, <= 0 uses 15
+	TrendingRefreshMinutes int
 }
 
 type SecurityConfig struct {
@@ -79,7 +87,11 @@ func getAppConfig() AppConfig {
 				RefreshTokenNotBeforeInMinutes: viper.GetInt("jwt.RefreshTokenNotBeforeInMinutes"),
 			},
 			Ro: RoConfig{
-				PresetLimit: viper.GetInt("ro.preset.limitPerUser"),
+				PresetLimit:            viper.GetInt("ro.preset.limitPerUser"),
+				AdminPresetLimit:       viper.GetInt("ro.preset.limitPerAdmin"),
+				RejectStatOverspent:    viper.GetBool("ro.preset.rejectStatOverspent"),
+				TrashRetentionDays:     viper.GetInt("ro.preset.trashRetentionDays"),
+				TrendingRefreshMinutes: viper.GetInt("ro.preset.trendingRefreshMinutes"),
 			},
 		}
 	}
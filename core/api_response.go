@@ -2,8 +2,14 @@ package core
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
 	"ro-backend/appError"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
 
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
@@ -13,6 +19,16 @@ type ErrorResponse struct {
 	Message string `json:"message"`
 }
 
+type ValidationErrorResponse struct {
+	Message string                `json:"message"`
+	Errors  []appError.FieldError `json:"errors"`
+}
+
+type VersionConflictResponse struct {
+	Message        string `json:"message"`
+	CurrentVersion int    `json:"currentVersion"`
+}
+
 func WriteErrObj(w http.ResponseWriter, httpStatus int, res interface{}) {
 	w.WriteHeader(httpStatus)
 	json.NewEncoder(w).Encode(res)
@@ -29,7 +45,7 @@ func WriteErr(w http.ResponseWriter, msg string) {
 	case primitive.ErrInvalidHex.Error():
 		httpStatus = http.StatusBadRequest
 
-	case appError.ErrNotMyPreset:
+	case appError.ErrNotMyPreset, appError.ErrNotMyFolder:
 		httpStatus = http.StatusNotFound
 		message = http.StatusText(httpStatus)
 	case appError.ErrInvalidPresetInput:
@@ -38,6 +54,40 @@ func WriteErr(w http.ResponseWriter, msg string) {
 		httpStatus = http.StatusBadRequest
 	case appError.ErrCannotUpdatePublishedPreset:
 		httpStatus = http.StatusBadRequest
+	case appError.ErrBadInput:
+		httpStatus = http.StatusBadRequest
+	case appError.ErrInvalidItemInput:
+		httpStatus = http.StatusBadRequest
+	case appError.ErrInvalidPresetExport:
+		httpStatus = http.StatusBadRequest
+	case appError.ErrUnsupportedPresetExport:
+		httpStatus = http.StatusBadRequest
+	case appError.ErrInvalidFolderInput:
+		httpStatus = http.StatusBadRequest
+	case appError.ErrFolderClassMismatch:
+		httpStatus = http.StatusBadRequest
+	case appError.ErrPresetAlreadyPublished:
+		httpStatus = http.StatusBadRequest
+	case appError.ErrPresetNotPublished:
+		httpStatus = http.StatusBadRequest
+	case appError.ErrInvalidPresetCursor:
+		httpStatus = http.StatusBadRequest
+	case appError.ErrInvalidTagInput, appError.ErrTagBanned:
+		httpStatus = http.StatusBadRequest
+	case appError.ErrTagAlreadyExists:
+		httpStatus = http.StatusConflict
+	case appError.ErrInvalidReportInput:
+		httpStatus = http.StatusBadRequest
+	case appError.ErrReportAlreadyOpen:
+		httpStatus = http.StatusConflict
+	case appError.ErrPresetVersionRequired:
+		httpStatus = http.StatusPreconditionRequired
+	case appError.ErrFolderLimitExceeded:
+		httpStatus = http.StatusForbidden
+	case appError.ErrPresetQuotaExceeded:
+		httpStatus = http.StatusForbidden
+	case appError.ErrUserInactive:
+		httpStatus = http.StatusForbidden
 	case appError.ErrForbidden:
 		httpStatus = http.StatusForbidden
 		message = http.StatusText(httpStatus)
@@ -54,6 +104,91 @@ func WriteErr(w http.ResponseWriter, msg string) {
 	json.NewEncoder(w).Encode(res)
 }
 
+// WriteError writes the field errors of a validation error, other errors go through WriteErr.
+func WriteError(w http.ResponseWriter, err error) {
+	var validationErr *appError.ValidationError
+	if errors.As(err, &validationErr) {
+		WriteErrObj(w, http.StatusBadRequest, ValidationErrorResponse{
+			Message: validationErr.Error(),
+			Errors:  validationErr.Errors,
+		})
+		return
+	}
+
+	var conflictErr *appError.VersionConflictError
+	if errors.As(err, &conflictErr) {
+		writeVersionConflict(w, http.StatusConflict, conflictErr)
+		return
+	}
+
+	WriteErr(w, err.Error())
+}
+
+// WriteConditionalError is WriteError for writes that carry a version,
+// a conflict is 412 when the version came from If-Match and 409 when it came in the body.
+func WriteConditionalError(w http.ResponseWriter, r *http.Request, err error) {
+	var conflictErr *appError.VersionConflictError
+	if errors.As(err, &conflictErr) && r.Header.Get("If-Match") != "" {
+		writeVersionConflict(w, http.StatusPreconditionFailed, conflictErr)
+		return
+	}
+
+	WriteError(w, err)
+}
+
+func writeVersionConflict(w http.ResponseWriter, httpStatus int, err *appError.VersionConflictError) {
+	SetETag(w, err.CurrentVersion)
+	WriteErrObj(w, httpStatus, VersionConflictResponse{
+		Message:        err.Error(),
+		CurrentVersion: err.CurrentVersion,
+	})
+}
+
+func SetETag(w http.ResponseWriter, version int) {
+	w.Header().Set("ETag", fmt.Sprintf(`"%v"`, version))
+}
+
+// ExpectedVersion reads the version a write was made against from If-Match,
+// bodyVersion is used when the header is not sent.
+// "*" and lists of ETags need the version the resource is at, current is only called for those:
+// "*" expects the current version, a list expects it when it is listed and its first version otherwise.
+func ExpectedVersion(r *http.Request, bodyVersion *int, current func() (int, error)) (*int, error) {
+	ifMatch := strings.TrimSpace(r.Header.Get("If-Match"))
+	if ifMatch == "" {
+		return bodyVersion, nil
+	}
+
+	matchAny := false
+	versions := []int{}
+	for _, tag := range strings.Split(ifMatch, ",") {
+		tag = strings.TrimSpace(tag)
+		if tag == "*" {
+			matchAny = true
+			continue
+		}
+
+		version, err := strconv.Atoi(strings.Trim(strings.TrimPrefix(tag, "W/"), `"`))
+		if err != nil {
+			return nil, fmt.Errorf(appError.ErrBadInput)
+		}
+		versions = append(versions, version)
+	}
+	if !matchAny && len(versions) == 1 {
+		return &versions[0], nil
+	}
+
+	version, err := current()
+	if err != nil {
+		return nil, err
+	}
+	if matchAny || slices.Contains(versions, version) {
+		return &version, nil
+	}
+
+	// none is current, the write fails with the version conflict
+	return &versions[0], nil
+}
+
 func WriteOK(w http.ResponseWriter, res interface{}) {
 	w.WriteHeader(http.StatusOK)
 	json.NewEncoder(w).Encode(res)
@@ -68,3 +203,7 @@ func WriteNoContent(w http.ResponseWriter, res interface{}) {
 	w.WriteHeader(http.StatusNoContent)
 	json.NewEncoder(w).Encode(res)
 }
+
+func SetPublicCache(w http.ResponseWriter, maxAge time.Duration) {
+	w.Header().Set("Cache-Control", fmt.Sprintf("public, max-age=%v", int(maxAge.Seconds())))
+}
@@ -0,0 +1,92 @@
+package core
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// bufferedResponseWriter holds the response back until the ETag of the whole body is known.
+type bufferedResponseWriter struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (w *bufferedResponseWriter) WriteHeader(status int) {
+	if w.status == 0 {
+		w.status = status
+	}
+}
+
+func (w *bufferedResponseWriter) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+
+	return w.body.Write(b)
+}
+
+// ConditionalGet gives 200 responses of GET requests an ETag and answers a matching If-None-Match with 304.
+// A handler that already set an ETag, like SetETag with the preset version, keeps it,
+// otherwise the ETag is a hash of the body. It works as a mux middleware or around one handler with WithETag.
+func ConditionalGet(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		bw := &bufferedResponseWriter{ResponseWriter: w}
+		next.ServeHTTP(bw, r)
+
+		status := bw.status
+		if status == 0 {
+			status = http.StatusOK
+		}
+		if status != http.StatusOK {
+			w.WriteHeader(status)
+			w.Write(bw.body.Bytes())
+			return
+		}
+
+		etag := w.Header().Get("ETag")
+		if etag == "" {
+			sum := sha256.Sum256(bw.body.Bytes())
+			etag = fmt.Sprintf(`"%x"`, sum[:16])
+			w.Header().Set("ETag", etag)
+		}
+
+		if etagMatches(r.Header.Get("If-None-Match"), etag) {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+		w.Write(bw.body.Bytes())
+	})
+}
+
+// WithETag opts a single route into ConditionalGet.
+func WithETag(handler func(http.ResponseWriter, *http.Request)) func(http.ResponseWriter, *http.Request) {
+	return ConditionalGet(http.HandlerFunc(handler)).ServeHTTP
+}
+
+// etagMatches compares weakly, as If-None-Match does.
+func etagMatches(ifNoneMatch, etag string) bool {
+	if ifNoneMatch == "" {
+		return false
+	}
+
+	etag = strings.TrimPrefix(etag, "W/")
+	for _, v := range strings.Split(ifNoneMatch, ",") {
+		v = strings.TrimSpace(v)
+		if v == "*" || strings.TrimPrefix(v, "W/") == etag {
+			return true
+		}
+	}
+
+	return false
+}
@@ -86,6 +86,10 @@ func (h authHandler) Login(w http.ResponseWriter, r *http.Request) {
 		core.WriteErr(w, appError.ErrUserNotFound)
 		return
 	}
+	if user.Status != repository.UserStatus.Active {
+		core.WriteErr(w, appError.ErrUserInactive)
+		return
+	}
 
 	generatedToken, err := h.tokenService.GenerateAccessToken(service.AccessTokenRequest{
 		UserId:    user.Id,
@@ -170,6 +174,10 @@ func (h authHandler) AuthenticationCallback(w http.ResponseWriter, r *http.Reque
 		core.WriteErr(w, err.Error())
 		return
 	}
+	if user.Status != repository.UserStatus.Active {
+		core.WriteErr(w, appError.ErrUserInactive)
+		return
+	}
 
 	code := uuid.NewString()
 	_, err = h.authenticationDataService.CreateAuthenticationData(service.AuthenticationDataRequest{
@@ -0,0 +1,21 @@
+package item_handler
+
+import (
+	"net/http"
+	"ro-backend/service"
+)
+
+func NewItemHandler(s service.ItemService) ItemHandler {
+	return itemHandler{service: s}
+}
+
+type ItemHandler interface {
+	FindItemById(w http.ResponseWriter, r *http.Request)
+	SearchItems(w http.ResponseWriter, r *http.Request)
+	LookupItems(w http.ResponseWriter, r *http.Request)
+	ImportItems(w http.ResponseWriter, r *http.Request)
+}
+
+type itemHandler struct {
+	service service.ItemService
+}
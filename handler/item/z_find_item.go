@@ -0,0 +1,26 @@
+package item_handler
+
+import (
+	"net/http"
+	"ro-backend/appError"
+	"ro-backend/core"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+func (h itemHandler) FindItemById(w http.ResponseWriter, r *http.Request) {
+	itemId, err := strconv.Atoi(mux.Vars(r)["itemId"])
+	if err != nil {
+		core.WriteErr(w, appError.ErrBadInput)
+		return
+	}
+
+	item, err := h.service.FindItemById(itemId)
+	if err != nil {
+		core.WriteErr(w, err.Error())
+		return
+	}
+
+	core.WriteOK(w, item)
+}
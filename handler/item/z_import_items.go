@@ -0,0 +1,37 @@
+package item_handler
+
+import (
+	"io"
+	"net/http"
+	"ro-backend/core"
+	"ro-backend/service"
+	"strings"
+)
+
+const maxItemFileSize = 50 << 20
+
+type ImportItemsResponse struct {
+	Total    int `json:"total"`
+	Upserted int `json:"upserted"`
+}
+
+func (h itemHandler) ImportItems(w http.ResponseWriter, r *http.Request) {
+	data, err := io.ReadAll(io.LimitReader(r.Body, maxItemFileSize))
+	if err != nil {
+		core.WriteErr(w, err.Error())
+		return
+	}
+
+	format := service.ItemFileFormat.Json
+	if r.URL.Query().Get("format") == service.ItemFileFormat.Yaml || strings.Contains(r.Header.Get("Content-Type"), "yaml") {
+		format = service.ItemFileFormat.Yaml
+	}
+
+	result, err := h.service.ImportItems(data, format)
+	if err != nil {
+		core.WriteErr(w, err.Error())
+		return
+	}
+
+	core.WriteOK(w, ImportItemsResponse(*result))
+}
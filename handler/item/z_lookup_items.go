@@ -0,0 +1,41 @@
+package item_handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"ro-backend/core"
+	"ro-backend/repository"
+)
+
+type LookupItemsRequest struct {
+	Ids []int `json:"ids"`
+}
+
+// LookupItemsResponse lists the ids that are not in the catalog in Unknown.
+type LookupItemsResponse struct {
+	Items   map[int]repository.Item `json:"items"`
+	Unknown []int                   `json:"unknown"`
+}
+
+func (h itemHandler) LookupItems(w http.ResponseWriter, r *http.Request) {
+	var d LookupItemsRequest
+	json.NewDecoder(r.Body).Decode(&d)
+
+	itemMap, err := h.service.FindItemMap(d.Ids)
+	if err != nil {
+		core.WriteErr(w, err.Error())
+		return
+	}
+
+	unknown := []int{}
+	for _, id := range d.Ids {
+		if _, found := itemMap[id]; !found {
+			unknown = append(unknown, id)
+		}
+	}
+
+	core.WriteOK(w, LookupItemsResponse{
+		Items:   itemMap,
+		Unknown: unknown,
+	})
+}
@@ -0,0 +1,67 @@
+package item_handler
+
+import (
+	"net/http"
+	"ro-backend/appError"
+	"ro-backend/core"
+	"ro-backend/repository"
+	"strconv"
+)
+
+const maxSearchTake = 100
+
+type SearchItemsResponse struct {
+	Items      []repository.Item `json:"items"`
+	TotalItems int               `json:"totalItem"`
+	Skip       int               `json:"skip"`
+	Take       int               `json:"take"`
+}
+
+func (h itemHandler) SearchItems(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	input := repository.SearchItemsInput{
+		Limit: 20,
+	}
+	if q := query.Get("q"); q != "" {
+		input.Name = &q
+	}
+	if itemType := query.Get("type"); itemType != "" {
+		input.Type = &itemType
+	}
+	if position := query.Get("position"); position != "" {
+		input.Position = &position
+	}
+
+	var err error
+	if query.Has("skip") {
+		input.Skip, err = strconv.Atoi(query.Get("skip"))
+		if err != nil {
+			core.WriteErr(w, appError.ErrBadInput)
+			return
+		}
+	}
+	if query.Has("take") {
+		input.Limit, err = strconv.Atoi(query.Get("take"))
+		if err != nil {
+			core.WriteErr(w, appError.ErrBadInput)
+			return
+		}
+	}
+	if input.Limit <= 0 || input.Limit > maxSearchTake {
+		input.Limit = maxSearchTake
+	}
+
+	result, err := h.service.SearchItems(input)
+	if err != nil {
+		core.WriteErr(w, err.Error())
+		return
+	}
+
+	core.WriteOK(w, SearchItemsResponse{
+		Items:      result.Items,
+		TotalItems: result.Total,
+		Skip:       input.Skip,
+		Take:       input.Limit,
+	})
+}
@@ -0,0 +1,171 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"ro-backend/appError"
+	"ro-backend/core"
+	"ro-backend/repository"
+	"ro-backend/service"
+
+	"github.com/gorilla/mux"
+)
+
+const moderationMaxTake = 100
+
+type ModerationHandler interface {
+	ReportPreset(http.ResponseWriter, *http.Request)
+	ReportTag(http.ResponseWriter, *http.Request)
+	GetQueue(http.ResponseWriter, *http.Request)
+	GetTargetReports(http.ResponseWriter, *http.Request)
+	Act(http.ResponseWriter, *http.Request)
+	GetAuditLogs(http.ResponseWriter, *http.Request)
+}
+
+func NewModerationHandler(s service.ModerationService) ModerationHandler {
+	return moderationHandler{s: s}
+}
+
+type moderationHandler struct {
+	s service.ModerationService
+}
+
+type ReportRequest struct {
+	Reason  string `json:"reason"`
+	Comment string `json:"comment"`
+}
+
+type ModerationActionRequest struct {
+	Note string `json:"note"`
+}
+
+type ModerationQueueResponse struct {
+	Items      []repository.ModerationQueueItem `json:"items"`
+	TotalItems int                              `json:"totalItem"`
+	Skip       int                              `json:"skip"`
+	Take       int                              `json:"take"`
+}
+
+type ModerationAuditLogsResponse struct {
+	Items      []repository.ModerationAuditLog `json:"items"`
+	TotalItems int                             `json:"totalItem"`
+	Skip       int                             `json:"skip"`
+	Take       int                             `json:"take"`
+}
+
+func (h moderationHandler) ReportPreset(w http.ResponseWriter, r *http.Request) {
+	h.report(w, r, repository.ReportTargetTypes.Preset, mux.Vars(r)["presetId"])
+}
+
+func (h moderationHandler) ReportTag(w http.ResponseWriter, r *http.Request) {
+	h.report(w, r, repository.ReportTargetTypes.Tag, mux.Vars(r)["tagId"])
+}
+
+func (h moderationHandler) report(w http.ResponseWriter, r *http.Request, targetType repository.ReportTargetType, targetId string) {
+	var d ReportRequest
+	err := json.NewDecoder(r.Body).Decode(&d)
+	if err != nil {
+		core.WriteErr(w, appError.ErrBadInput)
+		return
+	}
+
+	res, err := h.s.Report(service.ReportRequest{
+		TargetType: targetType,
+		TargetId:   targetId,
+		ReporterId: r.Header.Get("userId"),
+		Reason:     d.Reason,
+		Comment:    d.Comment,
+	})
+	if err != nil {
+		core.WriteErr(w, err.Error())
+		return
+	}
+
+	core.WriteCreated(w, res)
+}
+
+func (h moderationHandler) GetQueue(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	skip, take, err := parseSkipTake(query.Get("skip"), query.Get("take"), moderationMaxTake)
+	if err != nil {
+		core.WriteErr(w, err.Error())
+		return
+	}
+
+	res, err := h.s.FindQueue(skip, take)
+	if err != nil {
+		core.WriteErr(w, err.Error())
+		return
+	}
+
+	core.WriteOK(w, ModerationQueueResponse{
+		Items:      res.Items,
+		TotalItems: res.Total,
+		Skip:       skip,
+		Take:       take,
+	})
+}
+
+func (h moderationHandler) GetTargetReports(w http.ResponseWriter, r *http.Request) {
+	pathVars := mux.Vars(r)
+
+	res, err := h.s.FindOpenReports(repository.ReportTargetType(pathVars["targetType"]), pathVars["targetId"])
+	if err != nil {
+		core.WriteErr(w, err.Error())
+		return
+	}
+
+	core.WriteOK(w, res)
+}
+
+func (h moderationHandler) Act(w http.ResponseWriter, r *http.Request) {
+	var d ModerationActionRequest
+	err := json.NewDecoder(r.Body).Decode(&d)
+	if err != nil {
+		core.WriteErr(w, appError.ErrBadInput)
+		return
+	}
+
+	pathVars := mux.Vars(r)
+	res, err := h.s.Act(service.ModerationActionRequest{
+		AdminId:    r.Header.Get("userId"),
+		TargetType: repository.ReportTargetType(pathVars["targetType"]),
+		TargetId:   pathVars["targetId"],
+		Action:     repository.ModerationAction(pathVars["action"]),
+		Note:       d.Note,
+	})
+	if err != nil {
+		core.WriteErr(w, err.Error())
+		return
+	}
+
+	core.WriteOK(w, res)
+}
+
+func (h moderationHandler) GetAuditLogs(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	skip, take, err := parseSkipTake(query.Get("skip"), query.Get("take"), moderationMaxTake)
+	if err != nil {
+		core.WriteErr(w, err.Error())
+		return
+	}
+
+	res, err := h.s.FindAuditLogs(repository.FindAuditLogsInput{
+		PublisherId: query.Get("publisherId"),
+		Skip:        skip,
+		Take:        take,
+	})
+	if err != nil {
+		core.WriteErr(w, err.Error())
+		return
+	}
+
+	core.WriteOK(w, ModerationAuditLogsResponse{
+		Items:      res.Items,
+		TotalItems: res.Total,
+		Skip:       skip,
+		Take:       take,
+	})
+}
@@ -0,0 +1,38 @@
+package handler
+
+import (
+	"fmt"
+	"ro-backend/appError"
+	"strconv"
+)
+
+const defaultTake = 20
+
+// parseSkipTake reads optional skip/take query values, take falls back to 20 and is capped by maxTake.
+func parseSkipTake(rawSkip, rawTake string, maxTake int) (int, int, error) {
+	skip := 0
+	take := defaultTake
+
+	var err error
+	if rawSkip != "" {
+		skip, err = strconv.Atoi(rawSkip)
+		if err != nil || skip < 0 {
+			return 0, 0, fmt.Errorf(appError.ErrBadInput)
+		}
+	}
+
+	if rawTake != "" {
+		take, err = strconv.Atoi(rawTake)
+		if err != nil || take < 0 {
+			return 0, 0, fmt.Errorf(appError.ErrBadInput)
+		}
+	}
+	if take == 0 {
+		take = defaultTake
+	}
+	if take > maxTake {
+		take = maxTake
+	}
+
+	return skip, take, nil
+}
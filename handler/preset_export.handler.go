@@ -0,0 +1,98 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"ro-backend/appError"
+	"ro-backend/core"
+	"ro-backend/service"
+
+	"github.com/gorilla/mux"
+)
+
+type PresetExportHandlerParam struct {
+	PresetExportService service.PresetExportService
+	UserService         service.UserService
+}
+
+type PresetExportHandler interface {
+	ExportMyPreset(http.ResponseWriter, *http.Request)
+	ExportMyPresets(http.ResponseWriter, *http.Request)
+	ImportPresets(http.ResponseWriter, *http.Request)
+}
+
+func NewPresetExportHandler(p PresetExportHandlerParam) PresetExportHandler {
+	return presetExportHandler{
+		presetExportService: p.PresetExportService,
+		userService:         p.UserService,
+	}
+}
+
+type presetExportHandler struct {
+	presetExportService service.PresetExportService
+	userService         service.UserService
+}
+
+type PresetExportResponse struct {
+	Export    service.PresetExport `json:"export"`
+	ShareCode string               `json:"shareCode"`
+}
+
+// ImportPresetsRequest takes either the export json in data or its shareCode.
+type ImportPresetsRequest struct {
+	Data      json.RawMessage `json:"data"`
+	ShareCode string          `json:"shareCode"`
+}
+
+func (h presetExportHandler) ExportMyPreset(w http.ResponseWriter, r *http.Request) {
+	res, err := h.presetExportService.ExportPreset(service.CheckPresetOwnerRequest{
+		Id:     mux.Vars(r)["presetId"],
+		UserId: r.Header.Get("userId"),
+	})
+	if err != nil {
+		core.WriteErr(w, err.Error())
+		return
+	}
+
+	core.WriteOK(w, PresetExportResponse(*res))
+}
+
+func (h presetExportHandler) ExportMyPresets(w http.ResponseWriter, r *http.Request) {
+	res, err := h.presetExportService.ExportMyPresets(r.Header.Get("userId"))
+	if err != nil {
+		core.WriteErr(w, err.Error())
+		return
+	}
+
+	core.WriteOK(w, PresetExportResponse(*res))
+}
+
+func (h presetExportHandler) ImportPresets(w http.ResponseWriter, r *http.Request) {
+	var d ImportPresetsRequest
+	err := json.NewDecoder(r.Body).Decode(&d)
+	if err != nil || (len(d.Data) == 0 && d.ShareCode == "") {
+		core.WriteErr(w, appError.ErrBadInput)
+		return
+	}
+
+	userId := r.Header.Get("userId")
+	u, err := h.userService.FindUserById(userId)
+	if err != nil {
+		core.WriteErr(w, err.Error())
+		return
+	}
+
+	res, err := h.presetExportService.ImportPresets(service.ImportPresetsRequest{
+		UserId:    userId,
+		UserName:  u.Name,
+		Role:      u.Role,
+		Data:      d.Data,
+		ShareCode: d.ShareCode,
+	})
+	if err != nil {
+		core.WriteError(w, err)
+		return
+	}
+
+	core.WriteCreated(w, res)
+}
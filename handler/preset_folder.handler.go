@@ -0,0 +1,216 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"ro-backend/appError"
+	"ro-backend/core"
+	"ro-backend/service"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// unfiledFolderId lists presets outside of any folder, e.g. /me/ro_presets?folderId=none
+const unfiledFolderId = "none"
+
+type PresetFolderHandler interface {
+	GetMyFolders(http.ResponseWriter, *http.Request)
+	CreateFolder(http.ResponseWriter, *http.Request)
+	UpdateFolder(http.ResponseWriter, *http.Request)
+	ReorderFolders(http.ResponseWriter, *http.Request)
+	DeleteFolder(http.ResponseWriter, *http.Request)
+	MovePresetsToFolder(http.ResponseWriter, *http.Request)
+	RemovePresetsFromFolder(http.ResponseWriter, *http.Request)
+}
+
+func NewPresetFolderHandler(s service.PresetFolderService) PresetFolderHandler {
+	return presetFolderHandler{s: s}
+}
+
+type presetFolderHandler struct {
+	s service.PresetFolderService
+}
+
+type PresetFolderRequest struct {
+	Name    string `json:"name"`
+	ClassId *int   `json:"classId"`
+}
+
+type ReorderPresetFoldersRequest struct {
+	FolderIds []string `json:"folderIds"`
+}
+
+type MovePresetsRequest struct {
+	PresetIds []string `json:"presetIds"`
+}
+
+type PresetFolderResponse struct {
+	Id          string    `json:"id"`
+	Name        string    `json:"name"`
+	ClassId     int       `json:"classId"`
+	Order       int       `json:"order"`
+	TotalPreset int       `json:"totalPreset"`
+	CreatedAt   time.Time `json:"createdAt"`
+	UpdatedAt   time.Time `json:"updatedAt"`
+}
+
+type GetMyFoldersResponse struct {
+	Folders      []PresetFolderResponse `json:"folders"`
+	TotalUnfiled int                    `json:"totalUnfiled"`
+}
+
+type MovePresetsResponse struct {
+	Moved int `json:"moved"`
+}
+
+// parseFolderFilter returns nil when the listing is not filtered by folder.
+func parseFolderFilter(r *http.Request) *string {
+	query := r.URL.Query()
+	if !query.Has("folderId") {
+		return nil
+	}
+
+	folderId := query.Get("folderId")
+	if folderId == unfiledFolderId {
+		folderId = ""
+	}
+
+	return &folderId
+}
+
+func (h presetFolderHandler) GetMyFolders(w http.ResponseWriter, r *http.Request) {
+	res, err := h.s.FindMyFolders(r.Header.Get("userId"))
+	if err != nil {
+		core.WriteErr(w, err.Error())
+		return
+	}
+
+	response := GetMyFoldersResponse{
+		Folders:      []PresetFolderResponse{},
+		TotalUnfiled: res.TotalUnfiled,
+	}
+	for _, v := range res.Folders {
+		response.Folders = append(response.Folders, PresetFolderResponse{
+			Id:          v.Id,
+			Name:        v.Name,
+			ClassId:     v.ClassId,
+			Order:       v.Order,
+			TotalPreset: v.TotalPreset,
+			CreatedAt:   v.CreatedAt,
+			UpdatedAt:   v.UpdatedAt,
+		})
+	}
+
+	core.WriteOK(w, response)
+}
+
+func (h presetFolderHandler) CreateFolder(w http.ResponseWriter, r *http.Request) {
+	var d PresetFolderRequest
+	err := json.NewDecoder(r.Body).Decode(&d)
+	if err != nil {
+		core.WriteErr(w, appError.ErrBadInput)
+		return
+	}
+
+	classId := 0
+	if d.ClassId != nil {
+		classId = *d.ClassId
+	}
+
+	res, err := h.s.CreateFolder(service.CreatePresetFolderRequest{
+		UserId:  r.Header.Get("userId"),
+		Name:    d.Name,
+		ClassId: classId,
+	})
+	if err != nil {
+		core.WriteErr(w, err.Error())
+		return
+	}
+
+	core.WriteCreated(w, res)
+}
+
+func (h presetFolderHandler) UpdateFolder(w http.ResponseWriter, r *http.Request) {
+	var d PresetFolderRequest
+	err := json.NewDecoder(r.Body).Decode(&d)
+	if err != nil {
+		core.WriteErr(w, appError.ErrBadInput)
+		return
+	}
+
+	res, err := h.s.UpdateFolder(service.UpdatePresetFolderRequest{
+		Id:      mux.Vars(r)["folderId"],
+		UserId:  r.Header.Get("userId"),
+		Name:    d.Name,
+		ClassId: d.ClassId,
+	})
+	if err != nil {
+		core.WriteErr(w, err.Error())
+		return
+	}
+
+	core.WriteOK(w, res)
+}
+
+func (h presetFolderHandler) ReorderFolders(w http.ResponseWriter, r *http.Request) {
+	var d ReorderPresetFoldersRequest
+	err := json.NewDecoder(r.Body).Decode(&d)
+	if err != nil {
+		core.WriteErr(w, appError.ErrBadInput)
+		return
+	}
+
+	res, err := h.s.ReorderFolders(service.ReorderPresetFoldersRequest{
+		UserId:    r.Header.Get("userId"),
+		FolderIds: d.FolderIds,
+	})
+	if err != nil {
+		core.WriteErr(w, err.Error())
+		return
+	}
+
+	core.WriteOK(w, res)
+}
+
+func (h presetFolderHandler) DeleteFolder(w http.ResponseWriter, r *http.Request) {
+	err := h.s.DeleteFolder(service.PresetFolderRequest{
+		Id:     mux.Vars(r)["folderId"],
+		UserId: r.Header.Get("userId"),
+	})
+	if err != nil {
+		core.WriteErr(w, err.Error())
+		return
+	}
+
+	core.WriteNoContent(w, nil)
+}
+
+func (h presetFolderHandler) MovePresetsToFolder(w http.ResponseWriter, r *http.Request) {
+	h.movePresets(w, r, mux.Vars(r)["folderId"])
+}
+
+func (h presetFolderHandler) RemovePresetsFromFolder(w http.ResponseWriter, r *http.Request) {
+	h.movePresets(w, r, "")
+}
+
+func (h presetFolderHandler) movePresets(w http.ResponseWriter, r *http.Request, folderId string) {
+	var d MovePresetsRequest
+	err := json.NewDecoder(r.Body).Decode(&d)
+	if err != nil {
+		core.WriteErr(w, appError.ErrBadInput)
+		return
+	}
+
+	moved, err := h.s.MovePresets(service.MovePresetsRequest{
+		UserId:    r.Header.Get("userId"),
+		FolderId:  folderId,
+		PresetIds: d.PresetIds,
+	})
+	if err != nil {
+		core.WriteErr(w, err.Error())
+		return
+	}
+
+	core.WriteOK(w, MovePresetsResponse{Moved: moved})
+}
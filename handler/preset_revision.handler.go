@@ -0,0 +1,162 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"ro-backend/appError"
+	"ro-backend/core"
+	"ro-backend/service"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+type PresetRevisionHandler interface {
+	GetMyPresetRevisions(http.ResponseWriter, *http.Request)
+	GetMyPresetRevision(http.ResponseWriter, *http.Request)
+	DiffMyPresetRevisions(http.ResponseWriter, *http.Request)
+	RestoreMyPresetRevision(http.ResponseWriter, *http.Request)
+}
+
+func NewPresetRevisionHandler(s service.PresetRevisionService, roPresetService service.RoPresetService) PresetRevisionHandler {
+	return presetRevisionHandler{s: s, roPresetService: roPresetService}
+}
+
+type presetRevisionHandler struct {
+	s               service.PresetRevisionService
+	roPresetService service.RoPresetService
+}
+
+// RestorePresetRevisionRequest Version is used when If-Match is not sent.
+type RestorePresetRevisionRequest struct {
+	Version *int `json:"version"`
+}
+
+type PresetRevisionResponse struct {
+	Revision  int       `json:"revision"`
+	UserId    string    `json:"userId"`
+	Label     string    `json:"label"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+type DiffPresetRevisionsResponse struct {
+	PresetId string                         `json:"presetId"`
+	From     int                            `json:"from"`
+	To       int                            `json:"to"`
+	Changes  []service.PresetModelFieldDiff `json:"changes"`
+}
+
+func (h presetRevisionHandler) GetMyPresetRevisions(w http.ResponseWriter, r *http.Request) {
+	userId := r.Header.Get("userId")
+	presetId := mux.Vars(r)["presetId"]
+
+	res, err := h.s.FindRevisions(service.CheckPresetOwnerRequest{
+		Id:     presetId,
+		UserId: userId,
+	})
+	if err != nil {
+		core.WriteErr(w, err.Error())
+		return
+	}
+
+	response := []PresetRevisionResponse{}
+	for _, v := range res {
+		response = append(response, PresetRevisionResponse{
+			Revision:  v.Revision,
+			UserId:    v.UserId,
+			Label:     v.Label,
+			CreatedAt: v.CreatedAt,
+		})
+	}
+
+	core.WriteOK(w, response)
+}
+
+func (h presetRevisionHandler) GetMyPresetRevision(w http.ResponseWriter, r *http.Request) {
+	pathVars := mux.Vars(r)
+	revision, err := strconv.Atoi(pathVars["revision"])
+	if err != nil {
+		core.WriteErr(w, appError.ErrBadInput)
+		return
+	}
+
+	res, err := h.s.FindRevision(service.PresetRevisionRequest{
+		PresetId: pathVars["presetId"],
+		UserId:   r.Header.Get("userId"),
+		Revision: revision,
+	})
+	if err != nil {
+		core.WriteErr(w, err.Error())
+		return
+	}
+
+	core.WriteOK(w, res)
+}
+
+func (h presetRevisionHandler) DiffMyPresetRevisions(w http.ResponseWriter, r *http.Request) {
+	from, err := strconv.Atoi(r.URL.Query().Get("from"))
+	if err != nil {
+		core.WriteErr(w, appError.ErrBadInput)
+		return
+	}
+
+	to, err := strconv.Atoi(r.URL.Query().Get("to"))
+	if err != nil {
+		core.WriteErr(w, appError.ErrBadInput)
+		return
+	}
+
+	res, err := h.s.DiffRevisions(service.DiffPresetRevisionsRequest{
+		PresetId: mux.Vars(r)["presetId"],
+		UserId:   r.Header.Get("userId"),
+		From:     from,
+		To:       to,
+	})
+	if err != nil {
+		core.WriteErr(w, err.Error())
+		return
+	}
+
+	core.WriteOK(w, DiffPresetRevisionsResponse(*res))
+}
+
+func (h presetRevisionHandler) RestoreMyPresetRevision(w http.ResponseWriter, r *http.Request) {
+	pathVars := mux.Vars(r)
+	revision, err := strconv.Atoi(pathVars["revision"])
+	if err != nil {
+		core.WriteErr(w, appError.ErrBadInput)
+		return
+	}
+
+	var d RestorePresetRevisionRequest
+	json.NewDecoder(r.Body).Decode(&d)
+
+	presetId := pathVars["presetId"]
+	userId := r.Header.Get("userId")
+
+	version, err := core.ExpectedVersion(r, d.Version, currentPresetVersion(h.roPresetService, presetId, userId))
+	if err != nil {
+		core.WriteErr(w, err.Error())
+		return
+	}
+
+	res, err := h.s.RestoreRevision(service.RestorePresetRevisionRequest{
+		PresetId: presetId,
+		UserId:   userId,
+		Revision: revision,
+		Version:  version,
+	})
+	if err != nil {
+		core.WriteConditionalError(w, r, err)
+		return
+	}
+
+	var response GetMyPresetsResponse
+	response.From(service.PresetWithTags{
+		RoPreset: *res,
+	})
+
+	core.SetETag(w, res.Version)
+	core.WriteOK(w, response)
+}
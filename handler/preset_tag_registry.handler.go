@@ -0,0 +1,173 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"ro-backend/appError"
+	"ro-backend/core"
+	"ro-backend/repository"
+	"ro-backend/service"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+type PresetTagRegistryHandler interface {
+	GetTags(http.ResponseWriter, *http.Request)
+	SaveTag(http.ResponseWriter, *http.Request)
+	RenameTag(http.ResponseWriter, *http.Request)
+	MergeTags(http.ResponseWriter, *http.Request)
+	SuggestTags(http.ResponseWriter, *http.Request)
+}
+
+const suggestTagsDefaultLimit = 10
+const suggestTagsMaxLimit = 20
+
+func NewPresetTagRegistryHandler(s service.PresetTagRegistryService) PresetTagRegistryHandler {
+	return presetTagRegistryHandler{s: s}
+}
+
+type presetTagRegistryHandler struct {
+	s service.PresetTagRegistryService
+}
+
+type SavePresetTagRequest struct {
+	Slug     string   `json:"slug"`
+	NameEn   string   `json:"nameEn"`
+	NameTh   string   `json:"nameTh"`
+	Synonyms []string `json:"synonyms"`
+	Banned   bool     `json:"banned"`
+}
+
+type RenamePresetTagRequest struct {
+	To string `json:"to"`
+}
+
+type MergePresetTagsRequest struct {
+	From []string `json:"from"`
+	To   string   `json:"to"`
+}
+
+type PresetTagSuggestionResponse struct {
+	Tag         string `json:"tag"`
+	NameEn      string `json:"nameEn"`
+	NameTh      string `json:"nameTh"`
+	TotalPreset int    `json:"totalPreset"`
+	TotalLike   int    `json:"totalLike"`
+}
+
+type MergePresetTagsResponse struct {
+	Tag       repository.PresetTagDefinition `json:"tag"`
+	Rewritten int                            `json:"rewritten"`
+}
+
+func (h presetTagRegistryHandler) GetTags(w http.ResponseWriter, r *http.Request) {
+	res, err := h.s.FindTags()
+	if err != nil {
+		core.WriteErr(w, err.Error())
+		return
+	}
+
+	core.WriteOK(w, res)
+}
+
+func (h presetTagRegistryHandler) SaveTag(w http.ResponseWriter, r *http.Request) {
+	var d SavePresetTagRequest
+	err := json.NewDecoder(r.Body).Decode(&d)
+	if err != nil {
+		core.WriteErr(w, appError.ErrBadInput)
+		return
+	}
+
+	res, err := h.s.SaveTag(service.SavePresetTagRequest(d))
+	if err != nil {
+		core.WriteErr(w, err.Error())
+		return
+	}
+
+	core.WriteOK(w, res)
+}
+
+func (h presetTagRegistryHandler) RenameTag(w http.ResponseWriter, r *http.Request) {
+	var d RenamePresetTagRequest
+	err := json.NewDecoder(r.Body).Decode(&d)
+	if err != nil {
+		core.WriteErr(w, appError.ErrBadInput)
+		return
+	}
+
+	res, err := h.s.RenameTag(service.RenamePresetTagRequest{
+		Slug: mux.Vars(r)["slug"],
+		To:   d.To,
+	})
+	if err != nil {
+		core.WriteErr(w, err.Error())
+		return
+	}
+
+	core.WriteOK(w, MergePresetTagsResponse{Tag: res.Tag, Rewritten: res.Rewritten})
+}
+
+func (h presetTagRegistryHandler) MergeTags(w http.ResponseWriter, r *http.Request) {
+	var d MergePresetTagsRequest
+	err := json.NewDecoder(r.Body).Decode(&d)
+	if err != nil {
+		core.WriteErr(w, appError.ErrBadInput)
+		return
+	}
+
+	res, err := h.s.MergeTags(service.MergePresetTagsRequest(d))
+	if err != nil {
+		core.WriteErr(w, err.Error())
+		return
+	}
+
+	core.WriteOK(w, MergePresetTagsResponse{Tag: res.Tag, Rewritten: res.Rewritten})
+}
+
+func (h presetTagRegistryHandler) SuggestTags(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	classId := 0
+	if query.Get("classId") != "" {
+		v, err := strconv.Atoi(query.Get("classId"))
+		if err != nil || v < 0 {
+			core.WriteErr(w, appError.ErrBadInput)
+			return
+		}
+		classId = v
+	}
+
+	limit := suggestTagsDefaultLimit
+	if query.Get("limit") != "" {
+		v, err := strconv.Atoi(query.Get("limit"))
+		if err != nil || v <= 0 {
+			core.WriteErr(w, appError.ErrBadInput)
+			return
+		}
+		limit = min(v, suggestTagsMaxLimit)
+	}
+
+	res, err := h.s.SuggestTags(service.SuggestPresetTagsRequest{
+		Query:   query.Get("q"),
+		ClassId: classId,
+		Limit:   limit,
+	})
+	if err != nil {
+		core.WriteErr(w, err.Error())
+		return
+	}
+
+	response := []PresetTagSuggestionResponse{}
+	for _, v := range res {
+		response = append(response, PresetTagSuggestionResponse{
+			Tag:         v.Slug,
+			NameEn:      v.NameEn,
+			NameTh:      v.NameTh,
+			TotalPreset: v.TotalPreset,
+			TotalLike:   v.TotalLike,
+		})
+	}
+
+	core.WriteOK(w, response)
+}
@@ -0,0 +1,157 @@
+package handler
+
+import (
+	"net/http"
+	"ro-backend/appError"
+	"ro-backend/core"
+	"ro-backend/repository"
+	"ro-backend/service"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+const publicPresetCacheAge = 5 * time.Minute
+const publicPresetMaxTake = 100
+
+type PublicPresetHandlerParam struct {
+	RoPresetService  service.RoPresetService
+	PresetTagService service.PresetTagService
+}
+
+type PublicPresetHandler interface {
+	GetPublishedPreset(http.ResponseWriter, *http.Request)
+	SearchPublishedPresets(http.ResponseWriter, *http.Request)
+}
+
+func NewPublicPresetHandler(p PublicPresetHandlerParam) PublicPresetHandler {
+	return publicPresetHandler{
+		roPresetService:  p.RoPresetService,
+		presetTagService: p.PresetTagService,
+	}
+}
+
+type publicPresetHandler struct {
+	roPresetService  service.RoPresetService
+	presetTagService service.PresetTagService
+}
+
+type PublicPresetResponse struct {
+	Id            string                  `json:"id"`
+	PublishName   string                  `json:"publishName"`
+	PublisherName string                  `json:"publisherName"`
+	ClassId       int                     `json:"classId"`
+	PublishedAt   time.Time               `json:"publishedAt"`
+	ForkCount     int                     `json:"forkCount"`
+	Tags          map[string]int          `json:"tags"`
+	Model         *repository.PresetModel `json:"model,omitempty"`
+	SchemaVersion int                     `json:"schemaVersion,omitempty"`
+	// ItemOptions are the random options of the published copy
+	ItemOptions []repository.PresetItemOption `json:"itemOptions,omitempty"`
+}
+
+// From never exposes the owner id or the private label.
+func (r *PublicPresetResponse) From(p service.PresetWithTags, includeModel bool) {
+	r.Id = p.Id
+	r.PublishName = p.PublishName
+	r.PublisherName = p.UserName
+	r.ClassId = p.ClassId
+	r.PublishedAt = p.PublishedAt
+	r.ForkCount = p.ForkCount
+
+	r.Tags = map[string]int{}
+	for _, v := range p.Tags {
+		r.Tags[v.Tag] = v.TotalLike
+	}
+	if p.Published != nil {
+		r.ItemOptions = p.Published.ItemOptions
+	}
+
+	if includeModel {
+		model := p.Model
+		r.Model = &model
+		r.SchemaVersion = p.SchemaVersion
+	}
+}
+
+type SearchPublishedPresetsResponse struct {
+	Items      []PublicPresetResponse `json:"items"`
+	TotalItems int                    `json:"totalItem"`
+	Skip       int                    `json:"skip"`
+	Take       int                    `json:"take"`
+}
+
+func (h publicPresetHandler) GetPublishedPreset(w http.ResponseWriter, r *http.Request) {
+	presetId := mux.Vars(r)["presetId"]
+
+	res, err := h.roPresetService.FindPublishedPresetById(presetId)
+	if err != nil {
+		core.WriteErr(w, err.Error())
+		return
+	}
+
+	presetWithTags, err := h.presetTagService.AttachTags("", []repository.RoPreset{*res})
+	if err != nil {
+		core.WriteErr(w, err.Error())
+		return
+	}
+
+	var response PublicPresetResponse
+	response.From(presetWithTags[0], true)
+
+	core.SetPublicCache(w, publicPresetCacheAge)
+	core.WriteOK(w, response)
+}
+
+func (h publicPresetHandler) SearchPublishedPresets(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	skip, take, err := parseSkipTake(query.Get("skip"), query.Get("take"), publicPresetMaxTake)
+	if err != nil {
+		core.WriteErr(w, err.Error())
+		return
+	}
+
+	classId := 0
+	if query.Has("classId") {
+		classId, err = strconv.Atoi(query.Get("classId"))
+		if err != nil {
+			core.WriteErr(w, appError.ErrBadInput)
+			return
+		}
+	}
+
+	res, err := h.roPresetService.SearchPublishedPresets(service.SearchPublishedPresetsRequest{
+		ClassId: classId,
+		Skip:    skip,
+		Take:    take,
+	})
+	if err != nil {
+		core.WriteErr(w, err.Error())
+		return
+	}
+
+	presetWithTags, err := h.presetTagService.AttachTags("", res.Items)
+	if err != nil {
+		core.WriteErr(w, err.Error())
+		return
+	}
+
+	items := []PublicPresetResponse{}
+	for _, v := range presetWithTags {
+		var item PublicPresetResponse
+		item.From(v, false)
+		items = append(items, item)
+	}
+
+	response := SearchPublishedPresetsResponse{
+		Items:      items,
+		TotalItems: int(res.Total),
+		Skip:       skip,
+		Take:       take,
+	}
+
+	core.SetPublicCache(w, publicPresetCacheAge)
+	core.WriteOK(w, response)
+}
@@ -0,0 +1,122 @@
+package handler
+
+import (
+	"net/http"
+	"ro-backend/appError"
+	"ro-backend/core"
+	"ro-backend/service"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+const leaderboardMaxTake = 100
+
+type PublisherStatsHandler interface {
+	GetLeaderboard(http.ResponseWriter, *http.Request)
+	GetPublisherStats(http.ResponseWriter, *http.Request)
+}
+
+func NewPublisherStatsHandler(s service.PublisherStatsService) PublisherStatsHandler {
+	return publisherStatsHandler{s: s}
+}
+
+type publisherStatsHandler struct {
+	s service.PublisherStatsService
+}
+
+type PublisherTotalsResponse struct {
+	TotalLike      int `json:"totalLike"`
+	TotalPublished int `json:"totalPublished"`
+	TotalFork      int `json:"totalFork"`
+}
+
+type PublisherStatsResponse struct {
+	UserId   string `json:"userId"`
+	UserName string `json:"userName"`
+	PublisherTotalsResponse
+	ByClass map[int]PublisherTotalsResponse `json:"byClass,omitempty"`
+}
+
+func (r *PublisherStatsResponse) From(p service.PublisherStats) {
+	r.UserId = p.UserId
+	r.UserName = p.UserName
+	r.PublisherTotalsResponse = PublisherTotalsResponse(p.PublisherTotals)
+
+	if p.ByClass != nil {
+		r.ByClass = map[int]PublisherTotalsResponse{}
+		for classId, v := range p.ByClass {
+			r.ByClass[classId] = PublisherTotalsResponse(v)
+		}
+	}
+}
+
+type LeaderboardResponse struct {
+	Window     string                   `json:"window"`
+	Items      []PublisherStatsResponse `json:"items"`
+	TotalItems int                      `json:"totalItem"`
+	Skip       int                      `json:"skip"`
+	Take       int                      `json:"take"`
+}
+
+func (h publisherStatsHandler) GetLeaderboard(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	skip, take, err := parseSkipTake(query.Get("skip"), query.Get("take"), leaderboardMaxTake)
+	if err != nil {
+		core.WriteErr(w, err.Error())
+		return
+	}
+
+	classId := 0
+	if query.Has("classId") {
+		classId, err = strconv.Atoi(query.Get("classId"))
+		if err != nil {
+			core.WriteErr(w, appError.ErrBadInput)
+			return
+		}
+	}
+
+	res, err := h.s.Leaderboard(service.LeaderboardRequest{
+		ClassId: classId,
+		Window:  query.Get("window"),
+		Sort:    query.Get("sort"),
+		Skip:    skip,
+		Take:    take,
+	})
+	if err != nil {
+		core.WriteErr(w, err.Error())
+		return
+	}
+
+	response := LeaderboardResponse{
+		Window:     res.Window,
+		Items:      []PublisherStatsResponse{},
+		TotalItems: res.Total,
+		Skip:       skip,
+		Take:       take,
+	}
+	for _, v := range res.Items {
+		var item PublisherStatsResponse
+		item.From(v)
+		response.Items = append(response.Items, item)
+	}
+
+	core.WriteOK(w, response)
+}
+
+func (h publisherStatsHandler) GetPublisherStats(w http.ResponseWriter, r *http.Request) {
+	res, err := h.s.FindPublisherStats(service.PublisherStatsRequest{
+		UserId: mux.Vars(r)["userId"],
+		Window: r.URL.Query().Get("window"),
+	})
+	if err != nil {
+		core.WriteErr(w, err.Error())
+		return
+	}
+
+	var response PublisherStatsResponse
+	response.From(*res)
+
+	core.WriteOK(w, response)
+}
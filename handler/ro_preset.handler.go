@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"ro-backend/appError"
 	"ro-backend/core"
 	"ro-backend/repository"
 	"ro-backend/service"
@@ -13,17 +14,22 @@ import (
 	"github.com/gorilla/mux"
 )
 
+const myPresetsMaxLimit = 100
+const itemOptionTextsMaxTake = 500
+
 type RoPresetHandlerParam struct {
-	RoPresetService  service.RoPresetService
-	PresetTagService service.PresetTagService
-	UserService      service.UserService
+	RoPresetService       service.RoPresetService
+	PresetTagService      service.PresetTagService
+	UserService           service.UserService
+	PresetTrendingService service.PresetTrendingService
 }
 
 func NewRoPresetHandler(p RoPresetHandlerParam) RoPresetHandler {
 	return roPresetHandler{
-		roPresetService:  p.RoPresetService,
-		userService:      p.UserService,
-		presetTagService: p.PresetTagService,
+		roPresetService:       p.RoPresetService,
+		userService:           p.UserService,
+		presetTagService:      p.PresetTagService,
+		presetTrendingService: p.PresetTrendingService,
 	}
 }
 
@@ -37,18 +43,38 @@ type RoPresetHandler interface {
 	UpdateMyPreset(http.ResponseWriter, *http.Request)
 	PublishMyPreset(http.ResponseWriter, *http.Request)
 	UnPublishMyPreset(http.ResponseWriter, *http.Request)
+	RepublishMyPreset(http.ResponseWriter, *http.Request)
+	GetMyPublishedVersions(http.ResponseWriter, *http.Request)
+	GetMyPublishedVersion(http.ResponseWriter, *http.Request)
 	AddTags(http.ResponseWriter, *http.Request)
 	BulkOperationTags(http.ResponseWriter, *http.Request)
 	RemoveTags(http.ResponseWriter, *http.Request)
 	LikeTag(http.ResponseWriter, *http.Request)
 	UnLikeTag(http.ResponseWriter, *http.Request)
 	DeleteById(http.ResponseWriter, *http.Request)
+	GetMyTrash(http.ResponseWriter, *http.Request)
+	RestoreFromTrash(http.ResponseWriter, *http.Request)
+	PurgeFromTrash(http.ResponseWriter, *http.Request)
+	ForkPreset(http.ResponseWriter, *http.Request)
+	GetForkTree(http.ResponseWriter, *http.Request)
+	GetMyQuota(http.ResponseWriter, *http.Request)
+	CalcStatBudget(http.ResponseWriter, *http.Request)
+	DiffPresets(http.ResponseWriter, *http.Request)
+	SearchPresetsByItem(http.ResponseWriter, *http.Request)
+	SearchPresetsByItemOption(http.ResponseWriter, *http.Request)
+	GetTrending(http.ResponseWriter, *http.Request)
+	RebuildDerivedFields(http.ResponseWriter, *http.Request)
+	MigrateSchemaVersion(http.ResponseWriter, *http.Request)
+	MigrateTagLikes(http.ResponseWriter, *http.Request)
+	GetItemOptionTexts(http.ResponseWriter, *http.Request)
+	GetMyLikes(http.ResponseWriter, *http.Request)
 }
 
 type roPresetHandler struct {
-	roPresetService  service.RoPresetService
-	userService      service.UserService
-	presetTagService service.PresetTagService
+	roPresetService       service.RoPresetService
+	userService           service.UserService
+	presetTagService      service.PresetTagService
+	presetTrendingService service.PresetTrendingService
 }
 
 type PartialSearchRoPresetInput struct {
@@ -102,7 +128,41 @@ type GetMyPresetsResponse struct {
 	PublishName string         `json:"publishName"`
 	IsPublished bool           `json:"isPublished"`
 	PublishedAt time.Time      `json:"publishedAt"`
+	ForkedFrom  string         `json:"forkedFrom,omitempty"`
+	ForkCount   int            `json:"forkCount"`
+	FolderId    string         `json:"folderId,omitempty"`
 	Tags        []TagWithLiked `json:"tags"`
+
+	Version          int  `json:"version"`
+	StatOverspent    bool `json:"statOverspent"`
+	PublishedVersion int  `json:"publishedVersion,omitempty"`
+	// rawOptionTxts the server could not read
+	UnknownItemOptions []string `json:"unknownItemOptions,omitempty"`
+	// the draft was saved after it was last published
+	HasUnpublishedChanges bool `json:"hasUnpublishedChanges"`
+}
+
+type TrashPresetResponse struct {
+	Id          string    `json:"id"`
+	Label       string    `json:"label"`
+	ClassId     int       `json:"classId"`
+	PublishName string    `json:"publishName"`
+	IsPublished bool      `json:"isPublished"`
+	DeletedAt   time.Time `json:"deletedAt"`
+	// the preset is deleted for good after this
+	PurgeAt time.Time `json:"purgeAt"`
+}
+
+func (r *TrashPresetResponse) From(p repository.RoPreset) {
+	r.Id = p.Id
+	r.Label = p.Label
+	r.ClassId = p.ClassId
+	r.PublishName = p.PublishName
+	r.IsPublished = p.IsPublished
+	if p.DeletedAt != nil {
+		r.DeletedAt = *p.DeletedAt
+		r.PurgeAt = p.DeletedAt.Add(service.TrashRetention())
+	}
 }
 
 func (r *GetMyPresetsResponse) From(p service.PresetWithTags) {
@@ -114,6 +174,16 @@ func (r *GetMyPresetsResponse) From(p service.PresetWithTags) {
 	r.IsPublished = p.IsPublished
 	r.PublishedAt = p.PublishedAt
 	r.ClassId = p.ClassId
+	r.ForkedFrom = p.ForkedFrom
+	r.ForkCount = p.ForkCount
+	r.FolderId = p.FolderId
+	r.Version = p.Version
+	r.StatOverspent = p.StatOverspent
+	r.UnknownItemOptions = p.UnknownItemOptions
+	if p.IsPublished && p.Published != nil {
+		r.PublishedVersion = p.Published.Version
+		r.HasUnpublishedChanges = p.DraftChanged
+	}
 
 	tags := []TagWithLiked{}
 	for _, v := range p.Tags {
@@ -132,6 +202,12 @@ func (r *GetMyPresetsResponse) From(p service.PresetWithTags) {
 	r.Tags = tags
 }
 
+type GetMyPresetsPageResponse struct {
+	Items []GetMyPresetsResponse `json:"items"`
+	// NextCursor is empty on the last page
+	NextCursor string `json:"nextCursor"`
+}
+
 type GetMyEntirePresetsResponse struct {
 	Id          string                 `json:"id"`
 	Label       string                 `json:"label"`
@@ -140,8 +216,18 @@ type GetMyEntirePresetsResponse struct {
 	PublishName string                 `json:"publishName"`
 	IsPublished bool                   `json:"isPublished"`
 	PublishedAt time.Time              `json:"publishedAt"`
+	ForkedFrom  string                 `json:"forkedFrom,omitempty"`
+	ForkCount   int                    `json:"forkCount"`
+	FolderId    string                 `json:"folderId,omitempty"`
 	Tags        []TagWithLiked         `json:"tags"`
 	Model       repository.PresetModel `json:"model"`
+
+	Version       int  `json:"version"`
+	SchemaVersion int  `json:"schemaVersion"`
+	StatOverspent bool `json:"statOverspent"`
+
+	ItemOptions        []repository.PresetItemOption `json:"itemOptions"`
+	UnknownItemOptions []string                      `json:"unknownItemOptions"`
 }
 
 func (r *GetMyEntirePresetsResponse) From(p service.PresetWithTags) {
@@ -152,7 +238,15 @@ func (r *GetMyEntirePresetsResponse) From(p service.PresetWithTags) {
 	r.PublishName = p.PublishName
 	r.IsPublished = p.IsPublished
 	r.PublishedAt = p.PublishedAt
+	r.ForkedFrom = p.ForkedFrom
+	r.ForkCount = p.ForkCount
+	r.FolderId = p.FolderId
 	r.Model = p.Model
+	r.Version = p.Version
+	r.SchemaVersion = p.SchemaVersion
+	r.StatOverspent = p.StatOverspent
+	r.ItemOptions = p.ItemOptions
+	r.UnknownItemOptions = p.UnknownItemOptions
 
 	tags := []TagWithLiked{}
 	for _, v := range p.Tags {
@@ -171,6 +265,11 @@ func (r *GetMyEntirePresetsResponse) From(p service.PresetWithTags) {
 	r.Tags = tags
 }
 
+type GetMyEntirePresetsPageResponse struct {
+	Items      []GetMyEntirePresetsResponse `json:"items"`
+	NextCursor string                       `json:"nextCursor"`
+}
+
 type UpsertTagResponse struct {
 	Id        string    `json:"id"`
 	Label     string    `json:"label"`
@@ -194,6 +293,106 @@ type LikeTagResponse struct {
 
 type PublishPresetRequest struct {
 	PublishName string `json:"publishName"`
+	Version     *int   `json:"version"`
+}
+
+type DeletePresetRequest struct {
+	Version *int `json:"version"`
+}
+
+type PublishedVersionResponse struct {
+	Version     int                     `json:"version"`
+	PublishName string                  `json:"publishName"`
+	ClassId     int                     `json:"classId"`
+	PublishedAt time.Time               `json:"publishedAt"`
+	Model       *repository.PresetModel `json:"model,omitempty"`
+}
+
+type ForkPresetRequest struct {
+	Label string `json:"label"`
+}
+
+type PresetForkNodeResponse struct {
+	Id            string                   `json:"id"`
+	PublishName   string                   `json:"publishName"`
+	PublisherName string                   `json:"publisherName"`
+	IsPublished   bool                     `json:"isPublished"`
+	ForkCount     int                      `json:"forkCount"`
+	CreatedAt     time.Time                `json:"createdAt"`
+	Children      []PresetForkNodeResponse `json:"children"`
+	// HiddenForkCount counts the private forks of other users left out below this node
+	HiddenForkCount int `json:"hiddenForkCount"`
+}
+
+func (r *PresetForkNodeResponse) From(n service.PresetForkNode) {
+	r.Id = n.Id
+	r.PublishName = n.PublishName
+	r.PublisherName = n.PublisherName
+	r.IsPublished = n.IsPublished
+	r.ForkCount = n.ForkCount
+	r.CreatedAt = n.CreatedAt
+	r.HiddenForkCount = n.HiddenForkCount
+
+	children := []PresetForkNodeResponse{}
+	for _, v := range n.Children {
+		var child PresetForkNodeResponse
+		child.From(*v)
+		children = append(children, child)
+	}
+	r.Children = children
+}
+
+type GetMyQuotaResponse struct {
+	Used      int  `json:"used"`
+	Limit     int  `json:"limit"`
+	Unlimited bool `json:"unlimited"`
+}
+
+type RebuildDerivedFieldsResponse struct {
+	Total int `json:"total"`
+}
+
+type MigrateSchemaVersionResponse struct {
+	SchemaVersion int `json:"schemaVersion"`
+	Total         int `json:"total"`
+}
+
+type TrendingPresetResponse struct {
+	PublicPresetResponse
+	Score         float64 `json:"score"`
+	TrendingLikes int     `json:"trendingLikes"`
+}
+
+type TrendingTagResponse struct {
+	Tag           string  `json:"tag"`
+	Score         float64 `json:"score"`
+	TrendingLikes int     `json:"trendingLikes"`
+}
+
+type TrendingResponse struct {
+	Window     string                   `json:"window"`
+	Items      []TrendingPresetResponse `json:"items"`
+	TotalItems int                      `json:"totalItem"`
+	Skip       int                      `json:"skip"`
+	Take       int                      `json:"take"`
+	Tags       []TrendingTagResponse    `json:"tags"`
+	UpdatedAt  *time.Time               `json:"updatedAt,omitempty"`
+}
+
+type LikedPresetResponse struct {
+	PublicPresetResponse
+	LikedAt time.Time `json:"likedAt"`
+}
+
+type GetMyLikesResponse struct {
+	Items      []LikedPresetResponse `json:"items"`
+	TotalItems int                   `json:"totalItem"`
+	Skip       int                   `json:"skip"`
+	Take       int                   `json:"take"`
+}
+
+type MigrateTagLikesResponse struct {
+	Total int `json:"total"`
 }
 
 type BulkErrResponse struct {
@@ -387,6 +586,21 @@ func (h roPresetHandler) SearchPresetTags(w http.ResponseWriter, r *http.Request
 	core.WriteOK(w, response)
 }
 
+// currentPresetVersion is the version of a preset of the user, for If-Match with "*" or a list.
+func currentPresetVersion(s service.RoPresetService, presetId, userId string) func() (int, error) {
+	return func() (int, error) {
+		p, err := s.FindPresetById(service.CheckPresetOwnerRequest{
+			Id:     presetId,
+			UserId: userId,
+		})
+		if err != nil {
+			return 0, err
+		}
+
+		return p.Version, nil
+	}
+}
+
 func (h roPresetHandler) UpdateMyPreset(w http.ResponseWriter, r *http.Request) {
 	var d repository.UpdatePresetInput
 	json.NewDecoder(r.Body).Decode(&d)
@@ -394,19 +608,28 @@ func (h roPresetHandler) UpdateMyPreset(w http.ResponseWriter, r *http.Request)
 	presetId := mux.Vars(r)["presetId"]
 	d.UserId = r.Header.Get("userId")
 
-	res, err := h.roPresetService.UpdatePreset(presetId, d)
+	version, err := core.ExpectedVersion(r, d.Version, currentPresetVersion(h.roPresetService, presetId, d.UserId))
 	if err != nil {
 		core.WriteErr(w, err.Error())
 		return
 	}
+	d.Version = version
+
+	res, err := h.roPresetService.UpdatePreset(presetId, d)
+	if err != nil {
+		core.WriteConditionalError(w, r, err)
+		return
+	}
 
 	response := GetMyPresetsResponse{
 		Id:        res.Id,
 		Label:     res.Label,
 		CreatedAt: res.CreatedAt,
 		UpdatedAt: res.UpdatedAt,
+		Version:   res.Version,
 	}
 
+	core.SetETag(w, res.Version)
 	core.WriteOK(w, response)
 }
 
@@ -417,12 +640,19 @@ func (h roPresetHandler) PublishMyPreset(w http.ResponseWriter, r *http.Request)
 	presetId := mux.Vars(r)["presetId"]
 	userId := r.Header.Get("userId")
 
+	version, err := core.ExpectedVersion(r, d.Version, currentPresetVersion(h.roPresetService, presetId, userId))
+	if err != nil {
+		core.WriteErr(w, err.Error())
+		return
+	}
+
 	res, err := h.roPresetService.PublishPreset(presetId, repository.UpdatePresetInput{
 		PublishName: d.PublishName,
 		UserId:      userId,
+		Version:     version,
 	})
 	if err != nil {
-		core.WriteErr(w, err.Error())
+		core.WriteConditionalError(w, r, err)
 		return
 	}
 
@@ -431,6 +661,7 @@ func (h roPresetHandler) PublishMyPreset(w http.ResponseWriter, r *http.Request)
 		RoPreset: *res,
 	})
 
+	core.SetETag(w, res.Version)
 	core.WriteOK(w, response)
 }
 
@@ -455,10 +686,73 @@ func (h roPresetHandler) UnPublishMyPreset(w http.ResponseWriter, r *http.Reques
 }
 
 func (h roPresetHandler) DeleteById(w http.ResponseWriter, r *http.Request) {
+	var d DeletePresetRequest
+	json.NewDecoder(r.Body).Decode(&d)
+
+	userId := r.Header.Get("userId")
+	presetId := mux.Vars(r)["presetId"]
+
+	version, err := core.ExpectedVersion(r, d.Version, currentPresetVersion(h.roPresetService, presetId, userId))
+	if err != nil {
+		core.WriteErr(w, err.Error())
+		return
+	}
+
+	_, err = h.roPresetService.DeletePresetById(service.DeletePresetRequest{
+		Id:      presetId,
+		UserId:  userId,
+		Version: version,
+	})
+	if err != nil {
+		core.WriteConditionalError(w, r, err)
+		return
+	}
+
+	core.WriteNoContent(w, nil)
+}
+
+func (h roPresetHandler) GetMyTrash(w http.ResponseWriter, r *http.Request) {
+	userId := r.Header.Get("userId")
+
+	res, err := h.roPresetService.FindTrash(userId)
+	if err != nil {
+		core.WriteErr(w, err.Error())
+		return
+	}
+
+	response := []TrashPresetResponse{}
+	for _, v := range res {
+		var r TrashPresetResponse
+		r.From(v)
+		response = append(response, r)
+	}
+
+	core.WriteOK(w, response)
+}
+
+func (h roPresetHandler) RestoreFromTrash(w http.ResponseWriter, r *http.Request) {
+	userId := r.Header.Get("userId")
+	role := r.Header.Get("role")
+	presetId := mux.Vars(r)["presetId"]
+
+	res, err := h.roPresetService.RestorePreset(service.RestorePresetRequest{
+		Id:     presetId,
+		UserId: userId,
+		Role:   role,
+	})
+	if err != nil {
+		core.WriteErr(w, err.Error())
+		return
+	}
+
+	core.WriteOK(w, res)
+}
+
+func (h roPresetHandler) PurgeFromTrash(w http.ResponseWriter, r *http.Request) {
 	userId := r.Header.Get("userId")
 	presetId := mux.Vars(r)["presetId"]
 
-	_, err := h.roPresetService.DeletePresetById(service.CheckPresetOwnerRequest{
+	err := h.roPresetService.PurgePreset(service.CheckPresetOwnerRequest{
 		Id:     presetId,
 		UserId: userId,
 	})
@@ -506,10 +800,11 @@ func (h roPresetHandler) BulkCreatePresets(w http.ResponseWriter, r *http.Reques
 	}
 
 	d.UserName = u.Name
+	d.Role = u.Role
 
 	res, err := h.roPresetService.BulkCreatePresets(d)
 	if err != nil {
-		core.WriteErr(w, err.Error())
+		core.WriteError(w, err)
 		return
 	}
 
@@ -519,13 +814,20 @@ func (h roPresetHandler) BulkCreatePresets(w http.ResponseWriter, r *http.Reques
 func (h roPresetHandler) GetMyPresets(w http.ResponseWriter, r *http.Request) {
 	userId := r.Header.Get("userId")
 
-	res, err := h.roPresetService.FindPresetsByUserId(userId, false)
+	req, paged, err := parseMyPresetsQuery(r)
 	if err != nil {
 		core.WriteErr(w, err.Error())
 		return
 	}
+	req.UserId = userId
 
-	presetWithTags, err := h.presetTagService.AttachTags(userId, res)
+	res, err := h.roPresetService.FindMyPresets(req)
+	if err != nil {
+		core.WriteErr(w, err.Error())
+		return
+	}
+
+	presetWithTags, err := h.presetTagService.AttachTags(userId, res.Items)
 	if err != nil {
 		core.WriteErr(w, err.Error())
 		return
@@ -538,19 +840,35 @@ func (h roPresetHandler) GetMyPresets(w http.ResponseWriter, r *http.Request) {
 		response = append(response, r)
 	}
 
-	core.WriteOK(w, response)
+	if !paged {
+		core.WriteOK(w, response)
+		return
+	}
+
+	core.WriteOK(w, GetMyPresetsPageResponse{
+		Items:      response,
+		NextCursor: res.NextCursor,
+	})
 }
 
 func (h roPresetHandler) GetMyEntirePresets(w http.ResponseWriter, r *http.Request) {
 	userId := r.Header.Get("userId")
 
-	res, err := h.roPresetService.FindPresetsByUserId(userId, true)
+	req, paged, err := parseMyPresetsQuery(r)
 	if err != nil {
 		core.WriteErr(w, err.Error())
 		return
 	}
+	req.UserId = userId
+	req.IncludeModel = true
 
-	presetWithTags, err := h.presetTagService.AttachTags(userId, res)
+	res, err := h.roPresetService.FindMyPresets(req)
+	if err != nil {
+		core.WriteErr(w, err.Error())
+		return
+	}
+
+	presetWithTags, err := h.presetTagService.AttachTags(userId, res.Items)
 	if err != nil {
 		core.WriteErr(w, err.Error())
 		return
@@ -563,7 +881,54 @@ func (h roPresetHandler) GetMyEntirePresets(w http.ResponseWriter, r *http.Reque
 		response = append(response, r)
 	}
 
-	core.WriteOK(w, response)
+	if !paged {
+		core.WriteOK(w, response)
+		return
+	}
+
+	core.WriteOK(w, GetMyEntirePresetsPageResponse{
+		Items:      response,
+		NextCursor: res.NextCursor,
+	})
+}
+
+// parseMyPresetsQuery reads the filters of my preset lists. Without limit or cursor
+// every preset is returned as a plain array, the shape the lists had before paging.
+func parseMyPresetsQuery(r *http.Request) (service.FindMyPresetsRequest, bool, error) {
+	query := r.URL.Query()
+	req := service.FindMyPresetsRequest{
+		FolderId: parseFolderFilter(r),
+		Label:    query.Get("label"),
+		Sort:     query.Get("sort"),
+		Cursor:   query.Get("cursor"),
+	}
+
+	if query.Has("classId") {
+		classId, err := strconv.Atoi(query.Get("classId"))
+		if err != nil {
+			return req, false, fmt.Errorf(appError.ErrBadInput)
+		}
+		req.ClassId = &classId
+	}
+
+	if query.Has("published") {
+		published, err := strconv.ParseBool(query.Get("published"))
+		if err != nil {
+			return req, false, fmt.Errorf(appError.ErrBadInput)
+		}
+		req.IsPublished = &published
+	}
+
+	paged := query.Has("limit") || query.Has("cursor")
+	if paged {
+		_, limit, err := parseSkipTake("", query.Get("limit"), myPresetsMaxLimit)
+		if err != nil {
+			return req, false, err
+		}
+		req.Limit = limit
+	}
+
+	return req, paged, nil
 }
 
 func (h roPresetHandler) CreatePreset(w http.ResponseWriter, r *http.Request) {
@@ -582,10 +947,11 @@ func (h roPresetHandler) CreatePreset(w http.ResponseWriter, r *http.Request) {
 	}
 
 	d.UserName = u.Name
+	d.Role = u.Role
 
 	res, err := h.roPresetService.CreatePreset(d)
 	if err != nil {
-		core.WriteErr(w, err.Error())
+		core.WriteError(w, err)
 		return
 	}
 
@@ -605,5 +971,442 @@ func (h roPresetHandler) GetMyPresetById(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
+	core.SetETag(w, res.Version)
 	core.WriteOK(w, res)
 }
+
+func (h roPresetHandler) ForkPreset(w http.ResponseWriter, r *http.Request) {
+	var d ForkPresetRequest
+	json.NewDecoder(r.Body).Decode(&d)
+
+	presetId := mux.Vars(r)["presetId"]
+	userId := r.Header.Get("userId")
+
+	u, err := h.userService.FindUserById(userId)
+	if err != nil {
+		core.WriteErr(w, err.Error())
+		return
+	}
+
+	res, err := h.roPresetService.ForkPreset(service.ForkPresetRequest{
+		PresetId: presetId,
+		UserId:   userId,
+		UserName: u.Name,
+		Role:     u.Role,
+		Label:    d.Label,
+	})
+	if err != nil {
+		core.WriteErr(w, err.Error())
+		return
+	}
+
+	core.WriteCreated(w, res)
+}
+
+func (h roPresetHandler) GetForkTree(w http.ResponseWriter, r *http.Request) {
+	presetId := mux.Vars(r)["presetId"]
+	userId := r.Header.Get("userId")
+
+	res, err := h.roPresetService.FindForkTree(service.FindForkTreeRequest{
+		PresetId: presetId,
+		UserId:   userId,
+	})
+	if err != nil {
+		core.WriteErr(w, err.Error())
+		return
+	}
+
+	var response PresetForkNodeResponse
+	response.From(*res)
+
+	core.WriteOK(w, response)
+}
+
+func (h roPresetHandler) GetMyQuota(w http.ResponseWriter, r *http.Request) {
+	userId := r.Header.Get("userId")
+	role := r.Header.Get("role")
+
+	res, err := h.roPresetService.FindQuota(userId, role)
+	if err != nil {
+		core.WriteErr(w, err.Error())
+		return
+	}
+
+	core.WriteOK(w, GetMyQuotaResponse(*res))
+}
+
+func (h roPresetHandler) CalcStatBudget(w http.ResponseWriter, r *http.Request) {
+	var d repository.PresetModel
+	err := json.NewDecoder(r.Body).Decode(&d)
+	if err != nil {
+		core.WriteErr(w, appError.ErrBadInput)
+		return
+	}
+
+	core.WriteOK(w, h.roPresetService.CalcStatBudget(d))
+}
+
+func (h roPresetHandler) DiffPresets(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	a := query.Get("a")
+	b := query.Get("b")
+	if a == "" || b == "" {
+		core.WriteErr(w, appError.ErrBadInput)
+		return
+	}
+
+	res, err := h.roPresetService.DiffPresets(service.DiffPresetsRequest{
+		A:      a,
+		B:      b,
+		UserId: r.Header.Get("userId"),
+	})
+	if err != nil {
+		core.WriteErr(w, err.Error())
+		return
+	}
+
+	core.WriteOK(w, res)
+}
+
+func (h roPresetHandler) SearchPresetsByItem(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	itemId, err := strconv.Atoi(mux.Vars(r)["itemId"])
+	if err != nil {
+		core.WriteErr(w, appError.ErrBadInput)
+		return
+	}
+
+	skip, take, err := parseSkipTake(query.Get("skip"), query.Get("take"), publicPresetMaxTake)
+	if err != nil {
+		core.WriteErr(w, err.Error())
+		return
+	}
+
+	classId := 0
+	if query.Has("classId") {
+		classId, err = strconv.Atoi(query.Get("classId"))
+		if err != nil {
+			core.WriteErr(w, appError.ErrBadInput)
+			return
+		}
+	}
+
+	res, err := h.roPresetService.SearchPresetsByItem(service.SearchPresetsByItemRequest{
+		ItemId:  itemId,
+		ClassId: classId,
+		Slot:    query.Get("slot"),
+		Skill:   query.Get("skill"),
+		Sort:    query.Get("sort"),
+		Skip:    skip,
+		Take:    take,
+	})
+	if err != nil {
+		core.WriteErr(w, err.Error())
+		return
+	}
+
+	h.writePublishedPresets(w, r, res, skip, take)
+}
+
+func (h roPresetHandler) SearchPresetsByItemOption(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	skip, take, err := parseSkipTake(query.Get("skip"), query.Get("take"), publicPresetMaxTake)
+	if err != nil {
+		core.WriteErr(w, err.Error())
+		return
+	}
+
+	classId := 0
+	if query.Has("classId") {
+		classId, err = strconv.Atoi(query.Get("classId"))
+		if err != nil {
+			core.WriteErr(w, appError.ErrBadInput)
+			return
+		}
+	}
+
+	var minValue *int
+	if query.Has("minValue") {
+		v, err := strconv.Atoi(query.Get("minValue"))
+		if err != nil {
+			core.WriteErr(w, appError.ErrBadInput)
+			return
+		}
+		minValue = &v
+	}
+
+	res, err := h.roPresetService.SearchPresetsByItemOption(service.SearchPresetsByItemOptionRequest{
+		Option:   mux.Vars(r)["option"],
+		ClassId:  classId,
+		Slot:     query.Get("slot"),
+		Skill:    query.Get("skill"),
+		MinValue: minValue,
+		Sort:     query.Get("sort"),
+		Skip:     skip,
+		Take:     take,
+	})
+	if err != nil {
+		core.WriteErr(w, err.Error())
+		return
+	}
+
+	h.writePublishedPresets(w, r, res, skip, take)
+}
+
+func (h roPresetHandler) writePublishedPresets(w http.ResponseWriter, r *http.Request, res *repository.PartialSearchRoPresetResult, skip, take int) {
+	presetWithTags, err := h.presetTagService.AttachTags(r.Header.Get("userId"), res.Items)
+	if err != nil {
+		core.WriteErr(w, err.Error())
+		return
+	}
+
+	items := []PublicPresetResponse{}
+	for _, v := range presetWithTags {
+		var item PublicPresetResponse
+		item.From(v, false)
+		items = append(items, item)
+	}
+
+	core.WriteOK(w, SearchPublishedPresetsResponse{
+		Items:      items,
+		TotalItems: int(res.Total),
+		Skip:       skip,
+		Take:       take,
+	})
+}
+
+func (h roPresetHandler) RebuildDerivedFields(w http.ResponseWriter, r *http.Request) {
+	total, err := h.roPresetService.RebuildDerivedFields()
+	if err != nil {
+		core.WriteErr(w, err.Error())
+		return
+	}
+
+	core.WriteOK(w, RebuildDerivedFieldsResponse{Total: total})
+}
+
+func (h roPresetHandler) MigrateSchemaVersion(w http.ResponseWriter, r *http.Request) {
+	total, err := h.roPresetService.MigrateSchemaVersion()
+	if err != nil {
+		core.WriteErr(w, err.Error())
+		return
+	}
+
+	core.WriteOK(w, MigrateSchemaVersionResponse{
+		SchemaVersion: repository.PresetSchemaVersion,
+		Total:         total,
+	})
+}
+
+func (h roPresetHandler) GetItemOptionTexts(w http.ResponseWriter, r *http.Request) {
+	_, take, err := parseSkipTake("", r.URL.Query().Get("take"), itemOptionTextsMaxTake)
+	if err != nil {
+		core.WriteErr(w, err.Error())
+		return
+	}
+
+	res, err := h.roPresetService.SampleItemOptionTexts(take)
+	if err != nil {
+		core.WriteErr(w, err.Error())
+		return
+	}
+
+	core.WriteOK(w, res)
+}
+
+func (h roPresetHandler) MigrateTagLikes(w http.ResponseWriter, r *http.Request) {
+	total, err := h.presetTagService.MigrateLikes()
+	if err != nil {
+		core.WriteErr(w, err.Error())
+		return
+	}
+
+	core.WriteOK(w, MigrateTagLikesResponse{Total: total})
+}
+
+func (h roPresetHandler) RepublishMyPreset(w http.ResponseWriter, r *http.Request) {
+	var d PublishPresetRequest
+	json.NewDecoder(r.Body).Decode(&d)
+
+	presetId := mux.Vars(r)["presetId"]
+	userId := r.Header.Get("userId")
+
+	version, err := core.ExpectedVersion(r, d.Version, currentPresetVersion(h.roPresetService, presetId, userId))
+	if err != nil {
+		core.WriteErr(w, err.Error())
+		return
+	}
+
+	res, err := h.roPresetService.RepublishPreset(presetId, repository.UpdatePresetInput{
+		PublishName: d.PublishName,
+		UserId:      userId,
+		Version:     version,
+	})
+	if err != nil {
+		core.WriteConditionalError(w, r, err)
+		return
+	}
+
+	var response GetMyPresetsResponse
+	response.From(service.PresetWithTags{
+		RoPreset: *res,
+	})
+
+	core.SetETag(w, res.Version)
+	core.WriteOK(w, response)
+}
+
+func (h roPresetHandler) GetMyPublishedVersions(w http.ResponseWriter, r *http.Request) {
+	res, err := h.roPresetService.FindPublishedVersions(service.CheckPresetOwnerRequest{
+		Id:     mux.Vars(r)["presetId"],
+		UserId: r.Header.Get("userId"),
+	})
+	if err != nil {
+		core.WriteErr(w, err.Error())
+		return
+	}
+
+	response := []PublishedVersionResponse{}
+	for _, v := range res {
+		response = append(response, PublishedVersionResponse{
+			Version:     v.Version,
+			PublishName: v.PublishName,
+			ClassId:     v.ClassId,
+			PublishedAt: v.PublishedAt,
+		})
+	}
+
+	core.WriteOK(w, response)
+}
+
+func (h roPresetHandler) GetMyPublishedVersion(w http.ResponseWriter, r *http.Request) {
+	pathVars := mux.Vars(r)
+	version, err := strconv.Atoi(pathVars["version"])
+	if err != nil {
+		core.WriteErr(w, appError.ErrBadInput)
+		return
+	}
+
+	res, err := h.roPresetService.FindPublishedVersion(service.PublishedVersionRequest{
+		PresetId: pathVars["presetId"],
+		UserId:   r.Header.Get("userId"),
+		Version:  version,
+	})
+	if err != nil {
+		core.WriteErr(w, err.Error())
+		return
+	}
+
+	core.WriteOK(w, PublishedVersionResponse{
+		Version:     res.Version,
+		PublishName: res.PublishName,
+		ClassId:     res.ClassId,
+		PublishedAt: res.PublishedAt,
+		Model:       &res.Model,
+	})
+}
+
+func (h roPresetHandler) GetTrending(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	skip, take, err := parseSkipTake(query.Get("skip"), query.Get("take"), publicPresetMaxTake)
+	if err != nil {
+		core.WriteErr(w, err.Error())
+		return
+	}
+
+	classId := 0
+	if query.Has("classId") {
+		classId, err = strconv.Atoi(query.Get("classId"))
+		if err != nil {
+			core.WriteErr(w, appError.ErrBadInput)
+			return
+		}
+	}
+
+	res, err := h.presetTrendingService.FindTrending(service.FindTrendingRequest{
+		ClassId: classId,
+		Window:  query.Get("window"),
+		Skip:    skip,
+		Take:    take,
+	})
+	if err != nil {
+		core.WriteErr(w, err.Error())
+		return
+	}
+
+	presets := []repository.RoPreset{}
+	for _, v := range res.Presets {
+		presets = append(presets, v.RoPreset)
+	}
+	presetWithTags, err := h.presetTagService.AttachTags(r.Header.Get("userId"), presets)
+	if err != nil {
+		core.WriteErr(w, err.Error())
+		return
+	}
+
+	response := TrendingResponse{
+		Window:     string(res.Window),
+		Items:      []TrendingPresetResponse{},
+		TotalItems: res.Total,
+		Skip:       skip,
+		Take:       take,
+		Tags:       []TrendingTagResponse{},
+	}
+	for i, v := range presetWithTags {
+		item := TrendingPresetResponse{
+			Score:         res.Presets[i].Score,
+			TrendingLikes: res.Presets[i].TotalLike,
+		}
+		item.From(v, false)
+		response.Items = append(response.Items, item)
+	}
+	for _, v := range res.Tags {
+		response.Tags = append(response.Tags, TrendingTagResponse{
+			Tag:           v.Tag,
+			Score:         v.Score,
+			TrendingLikes: v.TotalLike,
+		})
+	}
+	if !res.UpdatedAt.IsZero() {
+		response.UpdatedAt = &res.UpdatedAt
+	}
+
+	core.WriteOK(w, response)
+}
+
+func (h roPresetHandler) GetMyLikes(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	skip, take, err := parseSkipTake(query.Get("skip"), query.Get("take"), publicPresetMaxTake)
+	if err != nil {
+		core.WriteErr(w, err.Error())
+		return
+	}
+
+	res, err := h.presetTagService.FindMyLikes(service.FindMyLikesRequest{
+		UserId: r.Header.Get("userId"),
+		Skip:   skip,
+		Take:   take,
+	})
+	if err != nil {
+		core.WriteErr(w, err.Error())
+		return
+	}
+
+	response := GetMyLikesResponse{
+		Items:      []LikedPresetResponse{},
+		TotalItems: res.Total,
+		Skip:       skip,
+		Take:       take,
+	}
+	for _, v := range res.Items {
+		item := LikedPresetResponse{LikedAt: v.LikedAt}
+		item.From(v.PresetWithTags, false)
+		response.Items = append(response.Items, item)
+	}
+
+	core.WriteOK(w, response)
+}
@@ -9,7 +9,9 @@ import (
 
 	"ro-backend/api_router"
 	"ro-backend/configuration"
+	"ro-backend/core"
 	"ro-backend/handler"
+	_itemHandler "ro-backend/handler/item"
 	"ro-backend/repository"
 	"ro-backend/service"
 
@@ -41,20 +43,55 @@ func main() {
 	var authDataRepo = repository.NewAuthenticationDataRepo(authDataCollection)
 	var refreshTokenRepo = repository.NewRefreshTokenRepo(refreshTokenCollection)
 	var roPresetRepo = repository.NewRoPresetRepository(roPresetCollection)
-	var roTagRepo = repository.NewPresetTagRepository(roTagCollection)
+	var roTagRepo = repository.NewPresetTagRepository(roTagCollection, presetTagLikeCollection, roPresetCollection)
+	var presetTrendingScoreRepo = repository.NewPresetTrendingScoreRepository(presetTrendingScoreCollection, roPresetCollection)
+	var publisherLeaderboardRepo = repository.NewPublisherLeaderboardRepository(publisherLeaderboardCollection)
+	var presetReportRepo = repository.NewPresetReportRepository(presetReportCollection)
+	var moderationAuditRepo = repository.NewModerationAuditRepository(moderationAuditCollection)
+	var presetTagDefinitionRepo = repository.NewPresetTagDefinitionRepository(presetTagDefinitionCollection)
+	var roPresetRevisionRepo = repository.NewPresetRevisionRepository(roPresetRevisionCollection)
+	var presetQuotaRepo = repository.NewPresetQuotaRepository(presetQuotaCollection)
+	var itemRepo = repository.NewItemRepository(itemCollection)
+	var presetFolderRepo = repository.NewPresetFolderRepository(presetFolderCollection)
+	var presetPublishedVersionRepo = repository.NewPresetPublishedVersionRepository(presetPublishedVersionCollection)
 	// var storeRepo = repository.NewStoreRepository(storeCollection)
 	// var productRepo = repository.NewProductRepository(productCollection)
 
 	var userService = service.NewUserService(userRepo, roPresetRepo)
 	var tokenService = service.NewTokenService(refreshTokenRepo)
 	var authDataService = service.NewAuthenticationDataService(authDataRepo)
-	var roPresetService = service.NewRoPresetService(roPresetRepo, roTagRepo)
-	var roTagService = service.NewPresetTagService(roTagRepo, roPresetRepo, userRepo)
+	var presetValidator = service.NewPresetValidator(itemRepo)
+	var roPresetService = service.NewRoPresetService(service.RoPresetServiceParam{
+		PresetRepo:   roPresetRepo,
+		TagRepo:      roTagRepo,
+		RevisionRepo: roPresetRevisionRepo,
+		QuotaRepo:    presetQuotaRepo,
+		VersionRepo:  presetPublishedVersionRepo,
+		UserRepo:     userRepo,
+		FolderRepo:   presetFolderRepo,
+		Validator:    presetValidator,
+	})
+	var presetRevisionService = service.NewPresetRevisionService(roPresetRepo, roPresetRevisionRepo, presetFolderRepo, presetValidator)
+	var presetExportService = service.NewPresetExportService(roPresetService)
+	var presetFolderService = service.NewPresetFolderService(presetFolderRepo, roPresetRepo)
+	var presetTagRegistryService = service.NewPresetTagRegistryService(presetTagDefinitionRepo, roTagRepo)
+	var roTagService = service.NewPresetTagService(roTagRepo, roPresetRepo, userRepo, presetTagRegistryService)
+	var presetTrendingService = service.NewPresetTrendingService(presetTrendingScoreRepo, roTagRepo, roPresetRepo)
+	var publisherStatsService = service.NewPublisherStatsService(roPresetRepo, roTagRepo, userRepo, publisherLeaderboardRepo)
+	var moderationService = service.NewModerationService(service.ModerationServiceParam{
+		ReportRepo:    presetReportRepo,
+		AuditRepo:     moderationAuditRepo,
+		PresetRepo:    roPresetRepo,
+		TagRepo:       roTagRepo,
+		UserRepo:      userRepo,
+		PresetService: roPresetService,
+	})
 	// var storeService = service.NewStoreService(storeRepo)
 	// var productService = service.NewProductService(productRepo, storeRepo)
 
 	var roPresetSummaryRepo = repository.NewRoPresetRepository(roPresetForSummaryCollection)
-	var presetSummaryService = service.NewSummaryPresetService(roPresetSummaryRepo)
+	var presetSummaryService = service.NewSummaryPresetService(roPresetSummaryRepo, itemRepo)
+	var itemService = service.NewItemService(itemRepo)
 
 	var authHandler = handler.NewAuthHandler(handler.AuthHandlerParam{
 		UserService:               userService,
@@ -63,16 +100,38 @@ func main() {
 	})
 	var userHandler = handler.NewUserHandler(userService)
 	var roPresetHandler = handler.NewRoPresetHandler(handler.RoPresetHandlerParam{
+		RoPresetService:       roPresetService,
+		UserService:           userService,
+		PresetTagService:      roTagService,
+		PresetTrendingService: presetTrendingService,
+	})
+	var presetRevisionHandler = handler.NewPresetRevisionHandler(presetRevisionService, roPresetService)
+	var presetFolderHandler = handler.NewPresetFolderHandler(presetFolderService)
+	var presetExportHandler = handler.NewPresetExportHandler(handler.PresetExportHandlerParam{
+		PresetExportService: presetExportService,
+		UserService:         userService,
+	})
+	var publicPresetHandler = handler.NewPublicPresetHandler(handler.PublicPresetHandlerParam{
 		RoPresetService:  roPresetService,
-		UserService:      userService,
 		PresetTagService: roTagService,
 	})
+	var presetTagRegistryHandler = handler.NewPresetTagRegistryHandler(presetTagRegistryService)
+	var moderationHandler = handler.NewModerationHandler(moderationService)
+	var publisherStatsHandler = handler.NewPublisherStatsHandler(publisherStatsService)
 	var presetSummaryHandler = handler.NewPresetSummaryHandler(presetSummaryService)
+	var itemHandler = _itemHandler.NewItemHandler(itemService)
 	// var storeHandler = _storeHandler.NewStoreHandler(storeService)
 	// var productHandler = _productHandler.NewProductHandler(productService)
 
 	var helpCheckHandler = handler.NewHelpCheckHandler()
 
+	backfillPublishedSnapshots(roPresetService)
+	migrateTagLikes(roTagService)
+	normalizePresetTags(presetTagRegistryService)
+	go purgeTrash(roPresetService)
+	go refreshTrending(presetTrendingService)
+	go refreshLeaderboard(publisherStatsService)
+
 	r := api_router.NewAppRouter(mux.NewRouter())
 	r.Use(jsonResponseMiddleware)
 	r.Use(rateLimitMiddleware)
@@ -85,12 +144,32 @@ func main() {
 	r.Post("/login", authHandler.Login)
 	r.Post("/refresh_token", authHandler.RefreshToken)
 
+	// ------ no authentication, published presets only
+	public := r.SubRouter("/public")
+	public.Use(core.ConditionalGet)
+	public.Get("/presets", publicPresetHandler.SearchPublishedPresets)
+	public.Get("/presets/{presetId}", publicPresetHandler.GetPublishedPreset)
+	public.Get("/preset_tags", presetTagRegistryHandler.GetTags)
+
 	// ------
 	admin := r.SubRouter("/admin")
 	admin.Use(adminGuard)
 	if appConfig.Environment == "dev" {
 		admin.Post("/preset_summary", presetSummaryHandler.GenerateSummary)
 	}
+	admin.Post("/items/import", itemHandler.ImportItems)
+	admin.Post("/ro_presets/derived_fields/rebuild", roPresetHandler.RebuildDerivedFields)
+	admin.Post("/ro_presets/schema/migrate", roPresetHandler.MigrateSchemaVersion)
+	admin.Get("/ro_presets/item_option_texts", roPresetHandler.GetItemOptionTexts)
+	admin.Post("/preset_tags/likes/migrate", roPresetHandler.MigrateTagLikes)
+	admin.Get("/preset_tags", presetTagRegistryHandler.GetTags)
+	admin.Post("/preset_tags", presetTagRegistryHandler.SaveTag)
+	admin.Post("/preset_tags/merge", presetTagRegistryHandler.MergeTags)
+	admin.Post("/preset_tags/{slug}/rename", presetTagRegistryHandler.RenameTag)
+	admin.Get("/moderation/queue", moderationHandler.GetQueue)
+	admin.Get("/moderation/audit", moderationHandler.GetAuditLogs)
+	admin.Get("/moderation/{targetType}/{targetId}/reports", moderationHandler.GetTargetReports)
+	admin.Post("/moderation/{targetType}/{targetId}/{action:dismiss|unpublish|ban}", moderationHandler.Act)
 	api_router.SetupRouterFriend(friendTranslatorCollection, admin)
 
 	// ------
@@ -99,20 +178,44 @@ func main() {
 	me.Get("", userHandler.GetMyProfile)
 	me.Post("", userHandler.PatchMyProfile)
 	me.Post("/logout", authHandler.Logout)
+	me.Get("/quota", roPresetHandler.GetMyQuota)
+	me.Get("/likes", core.WithETag(roPresetHandler.GetMyLikes))
 	me.Post("/bulk_ro_presets", roPresetHandler.BulkCreatePresets)
-	me.Get("/ro_entire_presets", roPresetHandler.GetMyEntirePresets)
-	me.Get("/ro_presets", roPresetHandler.GetMyPresets)
+	me.Get("/ro_entire_presets", core.WithETag(roPresetHandler.GetMyEntirePresets))
+	me.Get("/ro_presets", core.WithETag(roPresetHandler.GetMyPresets))
+	me.Get("/export", presetExportHandler.ExportMyPresets)
+	me.Post("/import", presetExportHandler.ImportPresets)
 	me.Post("/ro_presets", roPresetHandler.CreatePreset)
 
 	me.Get("/ro_presets/{presetId}", roPresetHandler.GetMyPresetById)
 	me.Post("/ro_presets/{presetId}", roPresetHandler.UpdateMyPreset)
 	me.Delete("/ro_presets/{presetId}", roPresetHandler.DeleteById)
+	me.Get("/trash", core.WithETag(roPresetHandler.GetMyTrash))
+	me.Post("/trash/{presetId}/restore", roPresetHandler.RestoreFromTrash)
+	me.Delete("/trash/{presetId}", roPresetHandler.PurgeFromTrash)
+	me.Get("/ro_presets/{presetId}/export", presetExportHandler.ExportMyPreset)
 	me.Post("/ro_presets/{presetId}/publish", roPresetHandler.PublishMyPreset)
 	me.Delete("/ro_presets/{presetId}/publish", roPresetHandler.UnPublishMyPreset)
+	me.Post("/ro_presets/{presetId}/republish", roPresetHandler.RepublishMyPreset)
+	me.Get("/ro_presets/{presetId}/published_versions", roPresetHandler.GetMyPublishedVersions)
+	me.Get("/ro_presets/{presetId}/published_versions/{version:[0-9]+}", roPresetHandler.GetMyPublishedVersion)
+
+	me.Get("/ro_presets/{presetId}/revisions", presetRevisionHandler.GetMyPresetRevisions)
+	me.Get("/ro_presets/{presetId}/revisions/diff", presetRevisionHandler.DiffMyPresetRevisions)
+	me.Get("/ro_presets/{presetId}/revisions/{revision:[0-9]+}", presetRevisionHandler.GetMyPresetRevision)
+	me.Post("/ro_presets/{presetId}/revisions/{revision:[0-9]+}/restore", presetRevisionHandler.RestoreMyPresetRevision)
 
 	me.Post("/ro_presets/{presetId}/tags", roPresetHandler.BulkOperationTags)
 	me.Delete("/ro_presets/{presetId}/tags/{tagId}", roPresetHandler.RemoveTags)
 
+	me.Get("/ro_preset_folders", core.WithETag(presetFolderHandler.GetMyFolders))
+	me.Post("/ro_preset_folders", presetFolderHandler.CreateFolder)
+	me.Post("/ro_preset_folders/order", presetFolderHandler.ReorderFolders)
+	me.Post("/ro_preset_folders/{folderId}", presetFolderHandler.UpdateFolder)
+	me.Delete("/ro_preset_folders/{folderId}", presetFolderHandler.DeleteFolder)
+	me.Post("/ro_preset_folders/{folderId}/presets", presetFolderHandler.MovePresetsToFolder)
+	me.Delete("/ro_preset_folders/{folderId}/presets", presetFolderHandler.RemovePresetsFromFolder)
+
 	// me.Post("/store", storeHandler.UpdateStore)
 	// me.Get("/store", storeHandler.FindMyStore)
 	// me.Post("/products/search", productHandler.GetMyProductList)
@@ -125,7 +228,14 @@ func main() {
 	// ------
 	ro := r.SubRouter("/ro_presets")
 	ro.Use(userGuard)
-	ro.Get("/class_by_tags/{classId}/{tag}", roPresetHandler.SearchPresetTags)
+	ro.Get("/class_by_tags/{classId}/{tag}", core.WithETag(roPresetHandler.SearchPresetTags))
+	ro.Get("/diff", roPresetHandler.DiffPresets)
+	ro.Get("/trending", core.WithETag(roPresetHandler.GetTrending))
+	ro.Get("/by_item/{itemId:[0-9]+}", core.WithETag(roPresetHandler.SearchPresetsByItem))
+	ro.Get("/by_item_option/{option}", core.WithETag(roPresetHandler.SearchPresetsByItemOption))
+	ro.Post("/{presetId}/fork", roPresetHandler.ForkPreset)
+	ro.Post("/{presetId}/report", moderationHandler.ReportPreset)
+	ro.Get("/{presetId}/forks", roPresetHandler.GetForkTree)
 
 	// ------ store
 	// store := r.SubRouter("/store")
@@ -139,17 +249,38 @@ func main() {
 	// product.use(userGuard)
 	// product.Post("/search", productHandler.SearchProductList)
 
+	// ------
+	presets := r.SubRouter("/presets")
+	presets.Use(userGuard)
+	presets.Post("/stat_budget", roPresetHandler.CalcStatBudget)
+
+	// ------
+	publishers := r.SubRouter("/publishers")
+	publishers.Use(userGuard)
+	publishers.Get("/leaderboard", core.WithETag(publisherStatsHandler.GetLeaderboard))
+	publishers.Get("/{userId}/stats", core.WithETag(publisherStatsHandler.GetPublisherStats))
+
+	// ------
+	item := r.SubRouter("/items")
+	item.Use(userGuard)
+	item.Get("", itemHandler.SearchItems)
+	item.Get("/{itemId:[0-9]+}", itemHandler.FindItemById)
+	item.Post("/lookup", itemHandler.LookupItems)
+
 	// ------
 	tag := r.SubRouter("/preset_tags")
 	tag.Use(userGuard)
+	tag.Get("/suggest", core.WithETag(presetTagRegistryHandler.SuggestTags))
 	tag.Post("/{tagId}/like", roPresetHandler.LikeTag)
 	tag.Delete("/{tagId}/like", roPresetHandler.UnLikeTag)
+	tag.Post("/{tagId}/report", moderationHandler.ReportTag)
 
-	headersOk := handlers.AllowedHeaders([]string{"authorization", "Content-Type"})
+	headersOk := handlers.AllowedHeaders([]string{"authorization", "Content-Type", "If-Match", "If-None-Match"})
+	exposedHeaders := handlers.ExposedHeaders([]string{"ETag"})
 	origins := handlers.AllowedOrigins(appConfig.Security.AllowedOrigins)
 	methods := handlers.AllowedMethods([]string{http.MethodGet, http.MethodOptions, http.MethodPost, http.MethodDelete})
 	maxAge := handlers.MaxAge(86400)
-	h := handlers.CORS(headersOk, origins, methods, maxAge)(r.Router)
+	h := handlers.CORS(headersOk, exposedHeaders, origins, methods, maxAge)(r.Router)
 	h = handlers.CompressHandler(h)
 
 	appPort := appConfig.Port
@@ -188,6 +319,76 @@ func initTimeZone() {
 	time.Local = ict
 }
 
+// backfillPublishedSnapshots gives presets published before drafts existed their published copy,
+// public search only looks at that copy.
+func backfillPublishedSnapshots(s service.RoPresetService) {
+	total, err := s.BackfillPublishedSnapshots()
+	if err != nil {
+		log.Printf("backfill published snapshots: %v\n", err)
+	} else if total > 0 {
+		log.Printf("backfilled %v published snapshots\n", total)
+	}
+}
+
+// migrateTagLikes moves likes still kept on the tags into the likes collection before serving,
+// a like on a tag that was not migrated yet would be counted twice. Presets get their total_like counted too.
+func migrateTagLikes(s service.PresetTagService) {
+	total, err := s.MigrateLikes()
+	if err != nil {
+		panic(fmt.Errorf("fatal error migrate tag likes: %w", err))
+	}
+	if total > 0 {
+		log.Printf("migrated likes of %v preset tags\n", total)
+	}
+}
+
+// normalizePresetTags rewrites tags saved before the registry, searches only look for canonical slugs.
+func normalizePresetTags(s service.PresetTagRegistryService) {
+	total, err := s.NormalizeExistingTags()
+	if err != nil {
+		log.Printf("normalize preset tags: %v\n", err)
+	} else if total > 0 {
+		log.Printf("normalized %v preset tags\n", total)
+	}
+}
+
+// purgeTrash deletes presets that stayed in the trash longer than the retention period, once an hour.
+func purgeTrash(s service.RoPresetService) {
+	for {
+		total, err := s.PurgeDeletedPresets(time.Now().Add(-service.TrashRetention()))
+		if err != nil {
+			log.Printf("purge trash: %v\n", err)
+		} else if total > 0 {
+			log.Printf("purged %v presets from the trash\n", total)
+		}
+
+		time.Sleep(time.Hour)
+	}
+}
+
+func refreshTrending(s service.PresetTrendingService) {
+	for {
+		_, err := s.RefreshScores()
+		if err != nil {
+			log.Printf("refresh trending: %v\n", err)
+		}
+
+		time.Sleep(service.TrendingRefreshInterval())
+	}
+}
+
+// refreshLeaderboard runs on the trending schedule, the leaderboard is too costly to count per request.
+func refreshLeaderboard(s service.PublisherStatsService) {
+	for {
+		_, err := s.RefreshLeaderboard()
+		if err != nil {
+			log.Printf("refresh leaderboard: %v\n", err)
+		}
+
+		time.Sleep(service.TrendingRefreshInterval())
+	}
+}
+
 func jsonResponseMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Add("Content-Type", "application/json")
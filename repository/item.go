@@ -0,0 +1,72 @@
+package repository
+
+import "time"
+
+type ItemTypes struct {
+	Weapon  string
+	Armor   string
+	Card    string
+	Enchant string
+	Ammo    string
+	Pet     string
+	Costume string
+	Shadow  string
+	Etc     string
+}
+
+var ItemType = ItemTypes{
+	Weapon:  "weapon",
+	Armor:   "armor",
+	Card:    "card",
+	Enchant: "enchant",
+	Ammo:    "ammo",
+	Pet:     "pet",
+	Costume: "costume",
+	Shadow:  "shadow",
+	Etc:     "etc",
+}
+
+// Positions uses the equipment names of PresetModel, e.g. "weapon", "headUpper", "accLeft".
+// For cards and enchants it lists the equipment they can be put into.
+type Item struct {
+	Id              int       `bson:"id" json:"id"`
+	Name            string    `bson:"name" json:"name"`
+	NameTh          string    `bson:"name_th" json:"nameTh"`
+	Type            string    `bson:"type" json:"type"`
+	Positions       []string  `bson:"positions" json:"positions"`
+	Refinable       bool      `bson:"refinable" json:"refinable"`
+	CardSlots       int       `bson:"card_slots" json:"cardSlots"`
+	AllowedEnchants []int     `bson:"allowed_enchants" json:"allowedEnchants"`
+	UpdatedAt       time.Time `bson:"updated_at" json:"updatedAt"`
+}
+
+func (i Item) CanBeUsedAt(position string) bool {
+	for _, v := range i.Positions {
+		if v == position {
+			return true
+		}
+	}
+
+	return false
+}
+
+type SearchItemsInput struct {
+	Name     *string
+	Type     *string
+	Position *string
+	Skip     int
+	Limit    int
+}
+
+type SearchItemsResult struct {
+	Items []Item
+	Total int
+}
+
+type ItemRepository interface {
+	FindItemById(id int) (*Item, error)
+	FindItemsByIds(ids []int) ([]Item, error)
+	SearchItems(SearchItemsInput) (*SearchItemsResult, error)
+	UpsertItems([]Item) (int, error)
+	CountItems() (int, error)
+}
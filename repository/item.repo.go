@@ -0,0 +1,125 @@
+package repository
+
+import (
+	"context"
+	"regexp"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+func NewItemRepository(c *mongo.Collection) ItemRepository {
+	return itemRepo{c: c}
+}
+
+type itemRepo struct {
+	c *mongo.Collection
+}
+
+func (r itemRepo) FindItemById(id int) (*Item, error) {
+	var item Item
+	err := r.c.FindOne(context.Background(), bson.M{"id": id}).Decode(&item)
+	if err != nil {
+		return nil, err
+	}
+
+	return &item, nil
+}
+
+func (r itemRepo) FindItemsByIds(ids []int) ([]Item, error) {
+	cursor, err := r.c.Find(context.Background(), bson.M{
+		"id": bson.M{
+			"$in": ids,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	items := []Item{}
+	err = cursor.All(context.Background(), &items)
+	if err != nil {
+		return nil, err
+	}
+
+	return items, nil
+}
+
+func (r itemRepo) SearchItems(i SearchItemsInput) (*SearchItemsResult, error) {
+	filter := bson.D{}
+	if i.Name != nil {
+		pattern := primitive.Regex{
+			Pattern: regexp.QuoteMeta(*i.Name),
+			Options: "i",
+		}
+		filter = append(filter, bson.E{Key: "$or", Value: bson.A{
+			bson.M{"name": pattern},
+			bson.M{"name_th": pattern},
+		}})
+	}
+	if i.Type != nil {
+		filter = append(filter, bson.E{Key: "type", Value: *i.Type})
+	}
+	if i.Position != nil {
+		filter = append(filter, bson.E{Key: "positions", Value: *i.Position})
+	}
+
+	total, err := r.c.CountDocuments(context.Background(), filter)
+	if err != nil {
+		return nil, err
+	}
+
+	cursor, err := r.c.Find(context.Background(), filter, options.Find().
+		SetSkip(int64(i.Skip)).
+		SetLimit(int64(i.Limit)).
+		SetSort(bson.M{"id": 1}))
+	if err != nil {
+		return nil, err
+	}
+
+	items := []Item{}
+	err = cursor.All(context.Background(), &items)
+	if err != nil {
+		return nil, err
+	}
+
+	return &SearchItemsResult{
+		Items: items,
+		Total: int(total),
+	}, nil
+}
+
+func (r itemRepo) UpsertItems(items []Item) (int, error) {
+	if len(items) == 0 {
+		return 0, nil
+	}
+
+	now := time.Now()
+	bulks := []mongo.WriteModel{}
+	for _, item := range items {
+		item.UpdatedAt = now
+		bulks = append(bulks, mongo.NewReplaceOneModel().
+			SetFilter(bson.M{"id": item.Id}).
+			SetReplacement(item).
+			SetUpsert(true))
+	}
+
+	res, err := r.c.BulkWrite(context.Background(), bulks)
+	if err != nil {
+		return 0, err
+	}
+
+	return int(res.UpsertedCount + res.ModifiedCount), nil
+}
+
+func (r itemRepo) CountItems() (int, error) {
+	total, err := r.c.EstimatedDocumentCount(context.Background())
+	if err != nil {
+		return 0, err
+	}
+
+	return int(total), nil
+}
@@ -0,0 +1,58 @@
+package repository
+
+import "time"
+
+type ModerationAction string
+
+var ModerationActions = struct {
+	Dismiss   ModerationAction
+	Unpublish ModerationAction
+	Ban       ModerationAction
+}{
+	Dismiss:   "dismiss",
+	Unpublish: "unpublish",
+	Ban:       "ban",
+}
+
+func (a ModerationAction) IsValid() bool {
+	switch a {
+	case ModerationActions.Dismiss, ModerationActions.Unpublish, ModerationActions.Ban:
+		return true
+	}
+
+	return false
+}
+
+// ModerationAuditLog is written for every moderator action, ReportIds are the reports it closed.
+type ModerationAuditLog struct {
+	Id          string           `bson:"id" json:"id"`
+	AdminId     string           `bson:"admin_id" json:"adminId"`
+	Action      ModerationAction `bson:"action" json:"action"`
+	TargetType  ReportTargetType `bson:"target_type" json:"targetType"`
+	TargetId    string           `bson:"target_id" json:"targetId"`
+	TargetLabel string           `bson:"target_label" json:"targetLabel"`
+	PresetId    string           `bson:"preset_id" json:"presetId"`
+	PublisherId string           `bson:"publisher_id" json:"publisherId"`
+	Note        string           `bson:"note" json:"note"`
+	ReportIds   []string         `bson:"report_ids" json:"reportIds"`
+	// TotalUnpublished is how many presets the action took down
+	TotalUnpublished int       `bson:"total_unpublished" json:"totalUnpublished"`
+	CreatedAt        time.Time `bson:"created_at" json:"createdAt"`
+}
+
+// FindAuditLogsInput PublisherId "" finds the logs of every publisher.
+type FindAuditLogsInput struct {
+	PublisherId string
+	Skip        int
+	Take        int
+}
+
+type FindAuditLogsResult struct {
+	Items []ModerationAuditLog
+	Total int
+}
+
+type ModerationAuditRepository interface {
+	CreateAuditLog(ModerationAuditLog) (*ModerationAuditLog, error)
+	FindAuditLogs(FindAuditLogsInput) (*FindAuditLogsResult, error)
+}
@@ -0,0 +1,63 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+func NewModerationAuditRepository(c *mongo.Collection) ModerationAuditRepository {
+	return moderationAuditRepo{c: c}
+}
+
+type moderationAuditRepo struct {
+	c *mongo.Collection
+}
+
+// CreateAuditLog only inserts, audit logs are never changed.
+func (r moderationAuditRepo) CreateAuditLog(l ModerationAuditLog) (*ModerationAuditLog, error) {
+	l.Id = uuid.NewString()
+	l.CreatedAt = time.Now()
+	if l.ReportIds == nil {
+		l.ReportIds = []string{}
+	}
+
+	_, err := r.c.InsertOne(context.Background(), l)
+	if err != nil {
+		return nil, err
+	}
+
+	return &l, nil
+}
+
+func (r moderationAuditRepo) FindAuditLogs(i FindAuditLogsInput) (*FindAuditLogsResult, error) {
+	filter := bson.M{}
+	if i.PublisherId != "" {
+		filter["publisher_id"] = i.PublisherId
+	}
+
+	total, err := r.c.CountDocuments(context.Background(), filter)
+	if err != nil {
+		return nil, err
+	}
+
+	cursor, err := r.c.Find(context.Background(), filter, options.Find().SetSkip(int64(i.Skip)).SetLimit(int64(i.Take)).SetSort(bson.M{"created_at": -1}))
+	if err != nil {
+		return nil, err
+	}
+
+	items := []ModerationAuditLog{}
+	err = cursor.All(context.Background(), &items)
+	if err != nil {
+		return nil, err
+	}
+
+	return &FindAuditLogsResult{
+		Items: items,
+		Total: int(total),
+	}, nil
+}
@@ -0,0 +1,166 @@
+package repository
+
+// PresetEquippedItem is kept on RoPreset so presets can be searched by item, card or enchant.
+type PresetEquippedItem struct {
+	Slot   string `bson:"slot" json:"slot"`
+	Field  string `bson:"field" json:"field"`
+	ItemId int    `bson:"item_id" json:"itemId"`
+}
+
+type PresetItemField struct {
+	Field  string
+	ItemId int
+}
+
+// PresetEquipment is one equipment position of PresetModel with everything put into it.
+// Position is the bson name of the item field, CardPosition is where its cards are compounded.
+type PresetEquipment struct {
+	Position     string
+	CardPosition string
+	ItemId       int
+	HasRefine    bool
+	Refine       int
+	Grade        string
+	Cards        []PresetItemField
+	Enchants     []PresetItemField
+}
+
+func (m PresetModel) Equipments() []PresetEquipment {
+	return []PresetEquipment{
+		{
+			Position: "weapon", CardPosition: "weapon", ItemId: m.Weapon, HasRefine: true, Refine: m.WeaponRefine, Grade: m.WeaponGrade,
+			Cards: []PresetItemField{
+				{"weaponCard1", m.WeaponCard1}, {"weaponCard2", m.WeaponCard2}, {"weaponCard3", m.WeaponCard3}, {"weaponCard4", m.WeaponCard4},
+			},
+			Enchants: []PresetItemField{
+				{"weaponEnchant0", m.WeaponEnchant0}, {"weaponEnchant1", m.WeaponEnchant1}, {"weaponEnchant2", m.WeaponEnchant2}, {"weaponEnchant3", m.WeaponEnchant3},
+			},
+		},
+		{
+			Position: "leftWeapon", CardPosition: "weapon", ItemId: m.LeftWeapon, HasRefine: true, Refine: m.LeftWeaponRefine, Grade: m.LeftWeaponGrade,
+			Cards: []PresetItemField{
+				{"leftWeaponCard1", m.LeftWeaponCard1}, {"leftWeaponCard2", m.LeftWeaponCard2}, {"leftWeaponCard3", m.LeftWeaponCard3}, {"leftWeaponCard4", m.LeftWeaponCard4},
+			},
+			Enchants: []PresetItemField{
+				{"leftWeaponEnchant0", m.LeftWeaponEnchant0}, {"leftWeaponEnchant1", m.LeftWeaponEnchant1}, {"leftWeaponEnchant2", m.LeftWeaponEnchant2}, {"leftWeaponEnchant3", m.LeftWeaponEnchant3},
+			},
+		},
+		{
+			Position: "shield", CardPosition: "shield", ItemId: m.Shield, HasRefine: true, Refine: m.ShieldRefine, Grade: m.ShieldGrade,
+			Cards:    []PresetItemField{{"shieldCard", m.ShieldCard}},
+			Enchants: []PresetItemField{{"shieldEnchant1", m.ShieldEnchant1}, {"shieldEnchant2", m.ShieldEnchant2}, {"shieldEnchant3", m.ShieldEnchant3}},
+		},
+		{
+			Position: "headUpper", CardPosition: "headUpper", ItemId: m.HeadUpper, HasRefine: true, Refine: m.HeadUpperRefine, Grade: m.HeadUpperGrade,
+			Cards:    []PresetItemField{{"headUpperCard", m.HeadUpperCard}},
+			Enchants: []PresetItemField{{"headUpperEnchant1", m.HeadUpperEnchant1}, {"headUpperEnchant2", m.HeadUpperEnchant2}, {"headUpperEnchant3", m.HeadUpperEnchant3}},
+		},
+		{
+			Position: "headMiddle", CardPosition: "headMiddle", ItemId: m.HeadMiddle, Grade: m.HeadMiddleGrade,
+			Cards:    []PresetItemField{{"headMiddleCard", m.HeadMiddleCard}},
+			Enchants: []PresetItemField{{"headMiddleEnchant1", m.HeadMiddleEnchant1}, {"headMiddleEnchant2", m.HeadMiddleEnchant2}, {"headMiddleEnchant3", m.HeadMiddleEnchant3}},
+		},
+		{
+			Position: "headLower", CardPosition: "headLower", ItemId: m.HeadLower, Grade: m.HeadLowerGrade,
+			Enchants: []PresetItemField{{"headLowerEnchant1", m.HeadLowerEnchant1}, {"headLowerEnchant2", m.HeadLowerEnchant2}, {"headLowerEnchant3", m.HeadLowerEnchant3}},
+		},
+		{
+			Position: "armor", CardPosition: "armor", ItemId: m.Armor, HasRefine: true, Refine: m.ArmorRefine, Grade: m.ArmorGrade,
+			Cards:    []PresetItemField{{"armorCard", m.ArmorCard}},
+			Enchants: []PresetItemField{{"armorEnchant1", m.ArmorEnchant1}, {"armorEnchant2", m.ArmorEnchant2}, {"armorEnchant3", m.ArmorEnchant3}},
+		},
+		{
+			Position: "garment", CardPosition: "garment", ItemId: m.Garment, HasRefine: true, Refine: m.GarmentRefine, Grade: m.GarmentGrade,
+			Cards:    []PresetItemField{{"garmentCard", m.GarmentCard}},
+			Enchants: []PresetItemField{{"garmentEnchant1", m.GarmentEnchant1}, {"garmentEnchant2", m.GarmentEnchant2}, {"garmentEnchant3", m.GarmentEnchant3}},
+		},
+		{
+			Position: "boot", CardPosition: "boot", ItemId: m.Boot, HasRefine: true, Refine: m.BootRefine, Grade: m.BootGrade,
+			Cards:    []PresetItemField{{"bootCard", m.BootCard}},
+			Enchants: []PresetItemField{{"bootEnchant1", m.BootEnchant1}, {"bootEnchant2", m.BootEnchant2}, {"bootEnchant3", m.BootEnchant3}},
+		},
+		{
+			Position: "accLeft", CardPosition: "accLeft", ItemId: m.AccLeft, HasRefine: true, Refine: m.AccLeftRefine, Grade: m.AccLeftGrade,
+			Cards:    []PresetItemField{{"accLeftCard", m.AccLeftCard}},
+			Enchants: []PresetItemField{{"accLeftEnchant1", m.AccLeftEnchant1}, {"accLeftEnchant2", m.AccLeftEnchant2}, {"accLeftEnchant3", m.AccLeftEnchant3}},
+		},
+		{
+			Position: "accRight", CardPosition: "accRight", ItemId: m.AccRight, HasRefine: true, Refine: m.AccRightRefine, Grade: m.AccRightGrade,
+			Cards:    []PresetItemField{{"accRightCard", m.AccRightCard}},
+			Enchants: []PresetItemField{{"accRightEnchant1", m.AccRightEnchant1}, {"accRightEnchant2", m.AccRightEnchant2}, {"accRightEnchant3", m.AccRightEnchant3}},
+		},
+		{Position: "ammo", ItemId: m.Ammo},
+		{Position: "pet", ItemId: m.Pet},
+		{
+			Position: "costumeUpper", ItemId: m.CostumeUpper,
+			Enchants: []PresetItemField{{"costumeEnchantUpper", m.CostumeEnchantUpper}},
+		},
+		{
+			Position: "costumeMiddle", ItemId: m.CostumeMiddle,
+			Enchants: []PresetItemField{{"costumeEnchantMiddle", m.CostumeEnchantMiddle}},
+		},
+		{
+			Position: "costumeLower", ItemId: m.CostumeLower,
+			Enchants: []PresetItemField{{"costumeEnchantLower", m.CostumeEnchantLower}},
+		},
+		{
+			Position: "costumeGarment", ItemId: m.CostumeGarment,
+			Enchants: []PresetItemField{
+				{"costumeEnchantGarment", m.CostumeEnchantGarment}, {"costumeEnchantGarment2", m.CostumeEnchantGarment2}, {"costumeEnchantGarment4", m.CostumeEnchantGarment4},
+			},
+		},
+		{
+			Position: "shadowWeapon", ItemId: m.ShadowWeapon, HasRefine: true, Refine: m.ShadowWeaponRefine,
+			Enchants: []PresetItemField{{"shadowWeaponEnchant2", m.ShadowWeaponEnchant2}, {"shadowWeaponEnchant3", m.ShadowWeaponEnchant3}},
+		},
+		{
+			Position: "shadowArmor", ItemId: m.ShadowArmor, HasRefine: true, Refine: m.ShadowArmorRefine,
+			Enchants: []PresetItemField{{"shadowArmorEnchant2", m.ShadowArmorEnchant2}, {"shadowArmorEnchant3", m.ShadowArmorEnchant3}},
+		},
+		{
+			Position: "shadowShield", ItemId: m.ShadowShield, HasRefine: true, Refine: m.ShadowShieldRefine,
+			Enchants: []PresetItemField{{"shadowShieldEnchant2", m.ShadowShieldEnchant2}, {"shadowShieldEnchant3", m.ShadowShieldEnchant3}},
+		},
+		{
+			Position: "shadowBoot", ItemId: m.ShadowBoot, HasRefine: true, Refine: m.ShadowBootRefine,
+			Enchants: []PresetItemField{{"shadowBootEnchant2", m.ShadowBootEnchant2}, {"shadowBootEnchant3", m.ShadowBootEnchant3}},
+		},
+		{
+			Position: "shadowEarring", ItemId: m.ShadowEarring, HasRefine: true, Refine: m.ShadowEarringRefine,
+			Enchants: []PresetItemField{{"shadowEarringEnchant2", m.ShadowEarringEnchant2}, {"shadowEarringEnchant3", m.ShadowEarringEnchant3}},
+		},
+		{
+			Position: "shadowPendant", ItemId: m.ShadowPendant, HasRefine: true, Refine: m.ShadowPendantRefine,
+			Enchants: []PresetItemField{{"shadowPendantEnchant2", m.ShadowPendantEnchant2}, {"shadowPendantEnchant3", m.ShadowPendantEnchant3}},
+		},
+	}
+}
+
+func (m PresetModel) EquippedItems() []PresetEquippedItem {
+	items := []PresetEquippedItem{}
+	for _, e := range m.Equipments() {
+		fields := append([]PresetItemField{{e.Position, e.ItemId}}, e.Cards...)
+		fields = append(fields, e.Enchants...)
+		for _, v := range fields {
+			if v.ItemId != 0 {
+				items = append(items, PresetEquippedItem{
+					Slot:   e.Position,
+					Field:  v.Field,
+					ItemId: v.ItemId,
+				})
+			}
+		}
+	}
+
+	return items
+}
+
+func IsPresetSlot(slot string) bool {
+	for _, e := range (PresetModel{}).Equipments() {
+		if e.Position == slot {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,38 @@
+package repository
+
+import "time"
+
+type PresetFolder struct {
+	Id     string `bson:"id" json:"id"`
+	UserId string `bson:"user_id" json:"userId"`
+	Name   string `bson:"name" json:"name"`
+	// ClassId 0 accepts presets of any class
+	ClassId   int       `bson:"class_id" json:"classId"`
+	Order     int       `bson:"order" json:"order"`
+	CreatedAt time.Time `bson:"created_at" json:"createdAt"`
+	UpdatedAt time.Time `bson:"updated_at" json:"updatedAt"`
+}
+
+type CreatePresetFolderInput struct {
+	UserId  string
+	Name    string
+	ClassId int
+	Order   int
+}
+
+type UpdatePresetFolderInput struct {
+	Name      string    `bson:"name,omitempty"`
+	ClassId   *int      `bson:"class_id,omitempty"`
+	UpdatedAt time.Time `bson:"updated_at"`
+}
+
+type PresetFolderRepository interface {
+	FindFolderById(id string) (*PresetFolder, error)
+	FindFoldersByUserId(userId string) ([]PresetFolder, error)
+	CountFoldersByUserId(userId string) (int, error)
+	FindMaxOrder(userId string) (int, error)
+	CreateFolder(CreatePresetFolderInput) (*PresetFolder, error)
+	UpdateFolder(id string, i UpdatePresetFolderInput) error
+	ReorderFolders(userId string, ids []string) error
+	DeleteFolder(id string) error
+}
@@ -0,0 +1,126 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+func NewPresetFolderRepository(c *mongo.Collection) PresetFolderRepository {
+	return presetFolderRepo{c: c}
+}
+
+type presetFolderRepo struct {
+	c *mongo.Collection
+}
+
+func (r presetFolderRepo) FindFolderById(id string) (*PresetFolder, error) {
+	var folder PresetFolder
+	err := r.c.FindOne(context.Background(), bson.M{"id": id}).Decode(&folder)
+	if err != nil {
+		return nil, err
+	}
+
+	return &folder, nil
+}
+
+func (r presetFolderRepo) FindFoldersByUserId(userId string) ([]PresetFolder, error) {
+	cursor, err := r.c.Find(context.Background(), bson.M{"user_id": userId}, options.Find().SetSort(bson.D{
+		{Key: "order", Value: 1},
+		{Key: "created_at", Value: 1},
+	}))
+	if err != nil {
+		return nil, err
+	}
+
+	folders := []PresetFolder{}
+	err = cursor.All(context.Background(), &folders)
+	if err != nil {
+		return nil, err
+	}
+
+	return folders, nil
+}
+
+func (r presetFolderRepo) CountFoldersByUserId(userId string) (int, error) {
+	total, err := r.c.CountDocuments(context.Background(), bson.M{"user_id": userId})
+	if err != nil {
+		return 0, err
+	}
+
+	return int(total), nil
+}
+
+// FindMaxOrder is -1 when the user has no folder.
+func (r presetFolderRepo) FindMaxOrder(userId string) (int, error) {
+	var folder PresetFolder
+	err := r.c.FindOne(context.Background(), bson.M{"user_id": userId}, options.FindOne().
+		SetSort(bson.D{{Key: "order", Value: -1}}).
+		SetProjection(bson.M{"order": 1})).Decode(&folder)
+	if err == mongo.ErrNoDocuments {
+		return -1, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	return folder.Order, nil
+}
+
+func (r presetFolderRepo) CreateFolder(i CreatePresetFolderInput) (*PresetFolder, error) {
+	now := time.Now()
+	folder := PresetFolder{
+		Id:        uuid.NewString(),
+		UserId:    i.UserId,
+		Name:      i.Name,
+		ClassId:   i.ClassId,
+		Order:     i.Order,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	_, err := r.c.InsertOne(context.Background(), folder)
+	if err != nil {
+		return nil, err
+	}
+
+	return &folder, nil
+}
+
+func (r presetFolderRepo) UpdateFolder(id string, i UpdatePresetFolderInput) error {
+	i.UpdatedAt = time.Now()
+	_, err := r.c.UpdateOne(context.Background(), bson.M{"id": id}, bson.M{
+		"$set": i,
+	})
+
+	return err
+}
+
+// ReorderFolders sets the order of each folder to its index in ids.
+func (r presetFolderRepo) ReorderFolders(userId string, ids []string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	now := time.Now()
+	writes := []mongo.WriteModel{}
+	for i, id := range ids {
+		writes = append(writes, mongo.NewUpdateOneModel().
+			SetFilter(bson.M{"id": id, "user_id": userId}).
+			SetUpdate(bson.M{"$set": bson.M{"order": i, "updated_at": now}}))
+	}
+
+	_, err := r.c.BulkWrite(context.Background(), writes)
+
+	return err
+}
+
+func (r presetFolderRepo) DeleteFolder(id string) error {
+	_, err := r.c.DeleteOne(context.Background(), bson.M{"id": id})
+
+	return err
+}
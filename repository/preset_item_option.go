@@ -0,0 +1,88 @@
+package repository
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var itemOptionNamePattern = regexp.MustCompile(`^[A-Za-z0-9_]+$`)
+
+// PresetItemOption is a random option of rawOptionTxts, kept on RoPreset so presets can be searched by option.
+// Index is where the text is in rawOptionTxts, Slot is empty when the text does not name one.
+type PresetItemOption struct {
+	Index  int    `bson:"index" json:"index"`
+	Slot   string `bson:"slot" json:"slot,omitempty"`
+	Option string `bson:"option" json:"option"`
+	Value  int    `bson:"value" json:"value"`
+}
+
+// ItemOptions parses rawOptionTxts, texts that are not "slot:option:value" or "option:value" are returned as unknown.
+// Empty entries are placeholders of the calculator and are skipped.
+func (m PresetModel) ItemOptions() ([]PresetItemOption, []string) {
+	options := []PresetItemOption{}
+	unknown := []string{}
+	for i, raw := range m.RawOptionTxts {
+		if raw == nil {
+			continue
+		}
+
+		txt, ok := raw.(string)
+		if !ok {
+			unknown = append(unknown, fmt.Sprintf("%v", raw))
+			continue
+		}
+		if strings.TrimSpace(txt) == "" {
+			continue
+		}
+
+		option, ok := ParseItemOption(txt)
+		if !ok {
+			unknown = append(unknown, txt)
+			continue
+		}
+
+		option.Index = i
+		options = append(options, option)
+	}
+
+	return options, unknown
+}
+
+// ParseItemOption reads "slot:option:value" or "option:value", the option is a name or an option id.
+// Values may have a sign and a trailing %, "weapon:atk:+5%" is weapon, atk, 5.
+// The grammar is not confirmed against what the calculator stores yet, check it against
+// /admin/ro_presets/item_option_texts before relying on the typed options.
+func ParseItemOption(txt string) (PresetItemOption, bool) {
+	parts := strings.Split(strings.TrimSpace(txt), ":")
+	for i := range parts {
+		parts[i] = strings.TrimSpace(parts[i])
+	}
+
+	var option PresetItemOption
+	switch len(parts) {
+	case 2:
+		option.Option = parts[0]
+	case 3:
+		if !IsPresetSlot(parts[0]) {
+			return option, false
+		}
+		option.Slot = parts[0]
+		option.Option = parts[1]
+	default:
+		return option, false
+	}
+
+	if !itemOptionNamePattern.MatchString(option.Option) {
+		return option, false
+	}
+
+	value, err := strconv.Atoi(strings.TrimSuffix(parts[len(parts)-1], "%"))
+	if err != nil {
+		return option, false
+	}
+	option.Value = value
+
+	return option, true
+}
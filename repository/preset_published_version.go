@@ -0,0 +1,27 @@
+package repository
+
+import "time"
+
+// PresetPublishedVersion keeps every snapshot that was published, newest version is the live one.
+type PresetPublishedVersion struct {
+	Id          string      `bson:"id" json:"id"`
+	PresetId    string      `bson:"preset_id" json:"presetId"`
+	Version     int         `bson:"version" json:"version"`
+	PublishName string      `bson:"publish_name" json:"publishName"`
+	ClassId     int         `bson:"class_id" json:"classId"`
+	Model       PresetModel `bson:"model" json:"model"`
+	PublishedAt time.Time   `bson:"published_at" json:"publishedAt"`
+}
+
+type FindPresetPublishedVersionInput struct {
+	PresetId string `bson:"preset_id"`
+	Version  int    `bson:"version"`
+}
+
+type PresetPublishedVersionRepository interface {
+	CreateVersion(PresetPublishedVersion) error
+	FindVersion(FindPresetPublishedVersionInput) (*PresetPublishedVersion, error)
+	FindVersionsByPresetId(presetId string) ([]PresetPublishedVersion, error)
+	FindLatestVersion(presetId string) (int, error)
+	DeleteVersionsByPresetId(presetId string) error
+}
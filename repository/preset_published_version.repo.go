@@ -0,0 +1,77 @@
+package repository
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+func NewPresetPublishedVersionRepository(c *mongo.Collection) PresetPublishedVersionRepository {
+	return presetPublishedVersionRepo{c: c}
+}
+
+type presetPublishedVersionRepo struct {
+	c *mongo.Collection
+}
+
+func (r presetPublishedVersionRepo) CreateVersion(v PresetPublishedVersion) error {
+	_, err := r.c.InsertOne(context.Background(), v)
+
+	return err
+}
+
+func (r presetPublishedVersionRepo) FindVersion(i FindPresetPublishedVersionInput) (*PresetPublishedVersion, error) {
+	var version PresetPublishedVersion
+	err := r.c.FindOne(context.Background(), i).Decode(&version)
+	if err != nil {
+		return nil, err
+	}
+	UpgradePresetModel(&version.Model, 0)
+
+	return &version, nil
+}
+
+func (r presetPublishedVersionRepo) FindVersionsByPresetId(presetId string) ([]PresetPublishedVersion, error) {
+	cursor, err := r.c.Find(context.Background(), bson.M{"preset_id": presetId}, options.Find().SetSort(bson.M{
+		"version": -1,
+	}).SetProjection(bson.M{
+		"model": 0,
+	}))
+	if err != nil {
+		return nil, err
+	}
+
+	versions := []PresetPublishedVersion{}
+	err = cursor.All(context.Background(), &versions)
+	if err != nil {
+		return nil, err
+	}
+
+	return versions, nil
+}
+
+// FindLatestVersion returns 0 when the preset was never published.
+func (r presetPublishedVersionRepo) FindLatestVersion(presetId string) (int, error) {
+	var last PresetPublishedVersion
+	err := r.c.FindOne(context.Background(), bson.M{"preset_id": presetId}, options.FindOne().SetSort(bson.M{
+		"version": -1,
+	}).SetProjection(bson.M{
+		"version": 1,
+	})).Decode(&last)
+	if err == mongo.ErrNoDocuments {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	return last.Version, nil
+}
+
+func (r presetPublishedVersionRepo) DeleteVersionsByPresetId(presetId string) error {
+	_, err := r.c.DeleteMany(context.Background(), bson.M{"preset_id": presetId})
+
+	return err
+}
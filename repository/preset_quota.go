@@ -0,0 +1,16 @@
+package repository
+
+import "time"
+
+type PresetQuota struct {
+	UserId    string    `bson:"user_id"`
+	Used      int       `bson:"used"`
+	UpdatedAt time.Time `bson:"updated_at"`
+}
+
+type PresetQuotaRepository interface {
+	FindQuota(userId string) (*PresetQuota, error)
+	InitQuota(userId string, used int) error
+	ReserveQuota(userId string, n, limit int) (bool, error)
+	ReleaseQuota(userId string, n int) error
+}
@@ -0,0 +1,71 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+func NewPresetQuotaRepository(c *mongo.Collection) PresetQuotaRepository {
+	return presetQuotaRepo{c: c}
+}
+
+type presetQuotaRepo struct {
+	c *mongo.Collection
+}
+
+func (r presetQuotaRepo) FindQuota(userId string) (*PresetQuota, error) {
+	var q PresetQuota
+	err := r.c.FindOne(context.Background(), bson.M{"user_id": userId}).Decode(&q)
+	if err != nil {
+		return nil, err
+	}
+
+	return &q, nil
+}
+
+// InitQuota only sets the usage when the user has no quota document yet.
+func (r presetQuotaRepo) InitQuota(userId string, used int) error {
+	_, err := r.c.UpdateOne(context.Background(), bson.M{"user_id": userId}, bson.M{
+		"$setOnInsert": PresetQuota{
+			UserId:    userId,
+			Used:      used,
+			UpdatedAt: time.Now(),
+		},
+	}, options.Update().SetUpsert(true))
+
+	return err
+}
+
+// ReserveQuota increases the usage by n only if it stays within limit, limit <= 0 means unlimited.
+func (r presetQuotaRepo) ReserveQuota(userId string, n, limit int) (bool, error) {
+	filter := bson.M{"user_id": userId}
+	if limit > 0 {
+		filter["used"] = bson.M{"$lte": limit - n}
+	}
+
+	res, err := r.c.UpdateOne(context.Background(), filter, bson.M{
+		"$inc": bson.M{"used": n},
+		"$set": bson.M{"updated_at": time.Now()},
+	})
+	if err != nil {
+		return false, err
+	}
+
+	return res.MatchedCount > 0, nil
+}
+
+func (r presetQuotaRepo) ReleaseQuota(userId string, n int) error {
+	_, err := r.c.UpdateOne(context.Background(), bson.M{
+		"user_id": userId,
+		"used":    bson.M{"$gte": n},
+	}, bson.M{
+		"$inc": bson.M{"used": -n},
+		"$set": bson.M{"updated_at": time.Now()},
+	})
+
+	return err
+}
@@ -0,0 +1,115 @@
+package repository
+
+import "time"
+
+type ReportTargetType string
+
+var ReportTargetTypes = struct {
+	Preset ReportTargetType
+	Tag    ReportTargetType
+}{
+	Preset: "preset",
+	Tag:    "tag",
+}
+
+func (t ReportTargetType) IsValid() bool {
+	return t == ReportTargetTypes.Preset || t == ReportTargetTypes.Tag
+}
+
+type ReportReason string
+
+var ReportReasons = struct {
+	OffensiveName ReportReason
+	Spam          ReportReason
+	Inappropriate ReportReason
+	Other         ReportReason
+}{
+	OffensiveName: "offensive_name",
+	Spam:          "spam",
+	Inappropriate: "inappropriate",
+	Other:         "other",
+}
+
+func (r ReportReason) IsValid() bool {
+	switch r {
+	case ReportReasons.OffensiveName, ReportReasons.Spam, ReportReasons.Inappropriate, ReportReasons.Other:
+		return true
+	}
+
+	return false
+}
+
+type ReportStatus string
+
+var ReportStatuses = struct {
+	Open      ReportStatus
+	Dismissed ReportStatus
+	Resolved  ReportStatus
+}{
+	Open:      "open",
+	Dismissed: "dismissed",
+	Resolved:  "resolved",
+}
+
+// PresetReport TargetId is the preset id or the tag id, TargetLabel is the publish name or tag when it was reported.
+type PresetReport struct {
+	Id          string           `bson:"id" json:"id"`
+	TargetType  ReportTargetType `bson:"target_type" json:"targetType"`
+	TargetId    string           `bson:"target_id" json:"targetId"`
+	TargetLabel string           `bson:"target_label" json:"targetLabel"`
+	PresetId    string           `bson:"preset_id" json:"presetId"`
+	PublisherId string           `bson:"publisher_id" json:"publisherId"`
+	ReporterId  string           `bson:"reporter_id" json:"reporterId"`
+	Reason      ReportReason     `bson:"reason" json:"reason"`
+	Comment     string           `bson:"comment" json:"comment"`
+	Status      ReportStatus     `bson:"status" json:"status"`
+	CreatedAt   time.Time        `bson:"created_at" json:"createdAt"`
+	// Action, ResolvedBy and ResolvedAt are set once a moderator acted on the report
+	Action     ModerationAction `bson:"action,omitempty" json:"action,omitempty"`
+	ResolvedBy string           `bson:"resolved_by,omitempty" json:"resolvedBy,omitempty"`
+	ResolvedAt *time.Time       `bson:"resolved_at,omitempty" json:"resolvedAt,omitempty"`
+}
+
+type CreateReportInput struct {
+	TargetType  ReportTargetType
+	TargetId    string
+	TargetLabel string
+	PresetId    string
+	PublisherId string
+	ReporterId  string
+	Reason      ReportReason
+	Comment     string
+}
+
+type ResolveReportsInput struct {
+	TargetType ReportTargetType
+	TargetId   string
+	Status     ReportStatus
+	Action     ModerationAction
+	ResolvedBy string
+}
+
+// ModerationQueueItem is one reported target with its open reports.
+type ModerationQueueItem struct {
+	TargetType     ReportTargetType `bson:"target_type" json:"targetType"`
+	TargetId       string           `bson:"target_id" json:"targetId"`
+	TargetLabel    string           `bson:"target_label" json:"targetLabel"`
+	PresetId       string           `bson:"preset_id" json:"presetId"`
+	PublisherId    string           `bson:"publisher_id" json:"publisherId"`
+	TotalReport    int              `bson:"total_report" json:"totalReport"`
+	Reasons        []ReportReason   `bson:"reasons" json:"reasons"`
+	FirstReportAt  time.Time        `bson:"first_report_at" json:"firstReportAt"`
+	LatestReportAt time.Time        `bson:"latest_report_at" json:"latestReportAt"`
+}
+
+type ModerationQueueResult struct {
+	Items []ModerationQueueItem
+	Total int
+}
+
+type PresetReportRepository interface {
+	CreateReport(CreateReportInput) (*PresetReport, error)
+	FindOpenReports(targetType ReportTargetType, targetId string) ([]PresetReport, error)
+	FindModerationQueue(skip, take int) (*ModerationQueueResult, error)
+	ResolveReports(ResolveReportsInput) ([]string, error)
+}
@@ -0,0 +1,154 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+func NewPresetReportRepository(c *mongo.Collection) PresetReportRepository {
+	return presetReportRepo{c: c}
+}
+
+type presetReportRepo struct {
+	c *mongo.Collection
+}
+
+// CreateReport fails with a duplicate key error when the reporter has an open report on the target.
+func (r presetReportRepo) CreateReport(i CreateReportInput) (*PresetReport, error) {
+	report := PresetReport{
+		Id:          uuid.NewString(),
+		TargetType:  i.TargetType,
+		TargetId:    i.TargetId,
+		TargetLabel: i.TargetLabel,
+		PresetId:    i.PresetId,
+		PublisherId: i.PublisherId,
+		ReporterId:  i.ReporterId,
+		Reason:      i.Reason,
+		Comment:     i.Comment,
+		Status:      ReportStatuses.Open,
+		CreatedAt:   time.Now(),
+	}
+
+	_, err := r.c.InsertOne(context.Background(), report)
+	if err != nil {
+		return nil, err
+	}
+
+	return &report, nil
+}
+
+func (r presetReportRepo) FindOpenReports(targetType ReportTargetType, targetId string) ([]PresetReport, error) {
+	cursor, err := r.c.Find(context.Background(), bson.M{
+		"target_type": targetType,
+		"target_id":   targetId,
+		"status":      ReportStatuses.Open,
+	}, options.Find().SetSort(bson.M{"created_at": -1}))
+	if err != nil {
+		return nil, err
+	}
+
+	reports := []PresetReport{}
+	err = cursor.All(context.Background(), &reports)
+	if err != nil {
+		return nil, err
+	}
+
+	return reports, nil
+}
+
+// FindModerationQueue groups the open reports by target, the most reported first.
+func (r presetReportRepo) FindModerationQueue(skip, take int) (*ModerationQueueResult, error) {
+	cursor, err := r.c.Aggregate(context.Background(), mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"status": ReportStatuses.Open}}},
+		{{Key: "$sort", Value: bson.M{"created_at": 1}}},
+		{{Key: "$group", Value: bson.M{
+			"_id":              bson.M{"target_type": "$target_type", "target_id": "$target_id"},
+			"target_label":     bson.M{"$last": "$target_label"},
+			"preset_id":        bson.M{"$last": "$preset_id"},
+			"publisher_id":     bson.M{"$last": "$publisher_id"},
+			"total_report":     bson.M{"$sum": 1},
+			"reasons":          bson.M{"$addToSet": "$reason"},
+			"first_report_at":  bson.M{"$first": "$created_at"},
+			"latest_report_at": bson.M{"$last": "$created_at"},
+		}}},
+		{{Key: "$set", Value: bson.M{
+			"target_type": "$_id.target_type",
+			"target_id":   "$_id.target_id",
+		}}},
+		{{Key: "$facet", Value: bson.M{
+			"total": bson.A{bson.M{"$count": "count"}},
+			"items": bson.A{
+				bson.M{"$sort": bson.D{{Key: "total_report", Value: -1}, {Key: "latest_report_at", Value: -1}}},
+				bson.M{"$skip": skip},
+				bson.M{"$limit": take},
+			},
+		}}},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var res []struct {
+		Total []struct {
+			Count int `bson:"count"`
+		} `bson:"total"`
+		Items []ModerationQueueItem `bson:"items"`
+	}
+	err = cursor.All(context.Background(), &res)
+	if err != nil {
+		return nil, err
+	}
+
+	queue := ModerationQueueResult{Items: []ModerationQueueItem{}}
+	if len(res) > 0 {
+		queue.Items = append(queue.Items, res[0].Items...)
+		if len(res[0].Total) > 0 {
+			queue.Total = res[0].Total[0].Count
+		}
+	}
+
+	return &queue, nil
+}
+
+// ResolveReports closes the open reports of a target and returns their ids.
+func (r presetReportRepo) ResolveReports(i ResolveReportsInput) ([]string, error) {
+	filter := bson.M{
+		"target_type": i.TargetType,
+		"target_id":   i.TargetId,
+		"status":      ReportStatuses.Open,
+	}
+
+	ids, err := r.c.Distinct(context.Background(), "id", filter)
+	if err != nil {
+		return nil, err
+	}
+
+	reportIds := []string{}
+	for _, v := range ids {
+		if id, ok := v.(string); ok {
+			reportIds = append(reportIds, id)
+		}
+	}
+	if len(reportIds) == 0 {
+		return reportIds, nil
+	}
+
+	_, err = r.c.UpdateMany(context.Background(), bson.M{"id": bson.M{"$in": reportIds}}, bson.M{
+		"$set": bson.M{
+			"status":      i.Status,
+			"action":      i.Action,
+			"resolved_by": i.ResolvedBy,
+			"resolved_at": time.Now(),
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return reportIds, nil
+}
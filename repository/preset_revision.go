@@ -0,0 +1,33 @@
+package repository
+
+import "time"
+
+type PresetRevision struct {
+	Id        string      `bson:"id" json:"id"`
+	PresetId  string      `bson:"preset_id" json:"presetId"`
+	Revision  int         `bson:"revision" json:"revision"`
+	UserId    string      `bson:"user_id" json:"userId"`
+	Label     string      `bson:"label" json:"label"`
+	Model     PresetModel `bson:"model" json:"model"`
+	CreatedAt time.Time   `bson:"created_at" json:"createdAt"`
+}
+
+type CreatePresetRevisionInput struct {
+	PresetId string
+	UserId   string
+	Label    string
+	Model    PresetModel
+}
+
+type FindPresetRevisionInput struct {
+	PresetId string `bson:"preset_id"`
+	Revision int    `bson:"revision"`
+}
+
+type PresetRevisionRepository interface {
+	CreateRevision(CreatePresetRevisionInput) (*PresetRevision, error)
+	FindRevision(FindPresetRevisionInput) (*PresetRevision, error)
+	FindRevisionsByPresetId(presetId string) ([]PresetRevision, error)
+	CountRevisionsByPresetId(presetId string) (int, error)
+	DeleteRevisionsByPresetId(presetId string) error
+}
@@ -0,0 +1,111 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+func NewPresetRevisionRepository(c *mongo.Collection) PresetRevisionRepository {
+	return presetRevisionRepo{c: c}
+}
+
+type presetRevisionRepo struct {
+	c *mongo.Collection
+}
+
+const createRevisionAttempts = 5
+
+// CreateRevision takes the next number after the latest revision, the unique (preset_id, revision) index
+// rejects a number taken by a concurrent save and the next one is tried.
+func (r presetRevisionRepo) CreateRevision(i CreatePresetRevisionInput) (*PresetRevision, error) {
+	var err error
+	for attempt := 0; attempt < createRevisionAttempts; attempt++ {
+		var revision *PresetRevision
+		revision, err = r.createNextRevision(i)
+		if !mongo.IsDuplicateKeyError(err) {
+			return revision, err
+		}
+	}
+
+	return nil, err
+}
+
+func (r presetRevisionRepo) createNextRevision(i CreatePresetRevisionInput) (*PresetRevision, error) {
+	latest := 0
+	var last PresetRevision
+	err := r.c.FindOne(context.Background(), bson.M{"preset_id": i.PresetId}, options.FindOne().SetSort(bson.M{
+		"revision": -1,
+	})).Decode(&last)
+	if err != nil && err != mongo.ErrNoDocuments {
+		return nil, err
+	}
+	if err == nil {
+		latest = last.Revision
+	}
+
+	revision := PresetRevision{
+		Id:        uuid.NewString(),
+		PresetId:  i.PresetId,
+		Revision:  latest + 1,
+		UserId:    i.UserId,
+		Label:     i.Label,
+		Model:     i.Model,
+		CreatedAt: time.Now(),
+	}
+	_, err = r.c.InsertOne(context.Background(), revision)
+	if err != nil {
+		return nil, err
+	}
+
+	return &revision, nil
+}
+
+func (r presetRevisionRepo) FindRevision(i FindPresetRevisionInput) (*PresetRevision, error) {
+	var revision PresetRevision
+	err := r.c.FindOne(context.Background(), i).Decode(&revision)
+	if err != nil {
+		return nil, err
+	}
+	UpgradePresetModel(&revision.Model, 0)
+
+	return &revision, nil
+}
+
+func (r presetRevisionRepo) FindRevisionsByPresetId(presetId string) ([]PresetRevision, error) {
+	cursor, err := r.c.Find(context.Background(), bson.M{"preset_id": presetId}, options.Find().SetSort(bson.M{
+		"revision": -1,
+	}).SetProjection(bson.M{
+		"model": 0,
+	}))
+	if err != nil {
+		return nil, err
+	}
+
+	revisions := []PresetRevision{}
+	err = cursor.All(context.Background(), &revisions)
+	if err != nil {
+		return nil, err
+	}
+
+	return revisions, nil
+}
+
+func (r presetRevisionRepo) CountRevisionsByPresetId(presetId string) (int, error) {
+	total, err := r.c.CountDocuments(context.Background(), bson.M{"preset_id": presetId})
+	if err != nil {
+		return 0, err
+	}
+
+	return int(total), nil
+}
+
+func (r presetRevisionRepo) DeleteRevisionsByPresetId(presetId string) error {
+	_, err := r.c.DeleteMany(context.Background(), bson.M{"preset_id": presetId})
+
+	return err
+}
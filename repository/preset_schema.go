@@ -0,0 +1,64 @@
+package repository
+
+// PresetSchemaVersion is the shape of PresetModel written by this version of the api,
+// presets saved before schema_version existed are version 0.
+const PresetSchemaVersion = 2
+
+// presetModelUpgrades[v] turns a version v model into version v+1.
+// Revisions and imports do not keep a version and run every step, so steps must be safe to repeat.
+var presetModelUpgrades = map[int]func(*PresetModel){
+	0: upgradePresetModelV0,
+	1: upgradePresetModelV1,
+}
+
+// UpgradePresetModel runs the upgrade steps from version and returns the version it ends with.
+func UpgradePresetModel(m *PresetModel, version int) int {
+	for version < PresetSchemaVersion {
+		upgrade, found := presetModelUpgrades[version]
+		if !found {
+			break
+		}
+
+		upgrade(m)
+		version++
+	}
+
+	return version
+}
+
+// upgradeModel only makes sense when the model was loaded.
+func (p *RoPreset) upgradeModel() {
+	p.SchemaVersion = UpgradePresetModel(&p.Model, p.SchemaVersion)
+	if p.Published != nil {
+		p.Published.SchemaVersion = UpgradePresetModel(&p.Published.Model, p.Published.SchemaVersion)
+	}
+}
+
+// upgradePresetModelV0 gives empty lists and maps instead of null, old documents miss the newer fields.
+func upgradePresetModelV0(m *PresetModel) {
+	if m.RawOptionTxts == nil {
+		m.RawOptionTxts = []interface{}{}
+	}
+	if m.SkillBuffMap == nil {
+		m.SkillBuffMap = map[string]int{}
+	}
+	if m.ActiveSkillMap == nil {
+		m.ActiveSkillMap = map[string]int{}
+	}
+	if m.PassiveSkillMap == nil {
+		m.PassiveSkillMap = map[string]int{}
+	}
+
+	for _, v := range []*[]int{&m.SkillBuffs, &m.ActiveSkills, &m.PassiveSkills, &m.Consumables, &m.Consumables2, &m.AspdPotions} {
+		if *v == nil {
+			*v = []int{}
+		}
+	}
+}
+
+// upgradePresetModelV1 moves the single aspdPotion into aspdPotions.
+func upgradePresetModelV1(m *PresetModel) {
+	if m.AspdPotion != 0 && len(m.AspdPotions) == 0 {
+		m.AspdPotions = []int{m.AspdPotion}
+	}
+}
@@ -1,24 +1,41 @@
 package repository
 
 import (
-	"slices"
 	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
+// NoTag is the placeholder tag every preset gets when it is published.
+const NoTag = "no_tag"
+
+// PresetTag TotalLike counts its PresetTagLike documents, it is only changed with $inc.
 type PresetTag struct {
 	Id          string    `bson:"_id,omitempty"`
 	PublisherId string    `bson:"publisher_id"`
 	Tag         string    `bson:"tag"`
 	ClassId     int       `bson:"class_id"`
 	PresetId    string    `bson:"preset_id"`
-	Likes       []string  `bson:"likes"`
 	TotalLike   int       `bson:"total_like"`
 	CreatedAt   time.Time `bson:"created_at"`
 	UpdatedAt   time.Time `bson:"updated_at"`
+	// DeletedAt follows the preset into the trash, likes are kept for a restore
+	DeletedAt *time.Time `bson:"deleted_at,omitempty"`
 }
 
-func (p *PresetTag) IsILike(userId string) bool {
-	return slices.Contains(p.Likes, userId)
+// PresetTagLike is one like of a tag, a user likes a tag at most once.
+type PresetTagLike struct {
+	TagId     primitive.ObjectID `bson:"tag_id"`
+	UserId    string             `bson:"user_id"`
+	CreatedAt time.Time          `bson:"created_at"`
+}
+
+// TagLikeEvent is one like of a tag, as read for the trending scores.
+type TagLikeEvent struct {
+	PresetId string    `bson:"preset_id"`
+	Tag      string    `bson:"tag"`
+	ClassId  int       `bson:"class_id"`
+	At       time.Time `bson:"at"`
 }
 
 type CreateTagInput struct {
@@ -28,15 +45,9 @@ type CreateTagInput struct {
 	PresetId    string
 }
 
-type PartialUpdateTagInput struct {
-	TotalLike int       `bson:"total_like"`
-	UpdatedAt time.Time `bson:"updated_at"`
-}
-
 type LikeTagInput struct {
-	Id        string
-	UserId    string
-	TotalLike int
+	Id     string
+	UserId string
 }
 
 type PartialSearchTagsInput struct {
@@ -44,6 +55,10 @@ type PartialSearchTagsInput struct {
 	Tag         string `bson:"tag,omitempty"`
 	ClassId     int    `bson:"class_id,omitempty"`
 	PresetId    string `bson:"preset_id,omitempty"`
+	// DeletedAt is always nil, tags of presets in the trash are left out
+	DeletedAt *time.Time `bson:"deleted_at"`
+	// TagAliases are matched as well as Tag, for tags saved before the registry normalized them
+	TagAliases []string `bson:"-"`
 }
 
 type PartialSearchSorting struct {
@@ -56,6 +71,32 @@ type PartialSearchTagsResult struct {
 	Total int
 }
 
+// SuggestTagsInput matches tags starting with Prefix or in Tags, ClassId 0 counts every class.
+type SuggestTagsInput struct {
+	Prefix      string
+	Tags        []string
+	ExcludeTags []string
+	ClassId     int
+	Limit       int
+}
+
+type TagSuggestion struct {
+	Tag         string `bson:"_id"`
+	TotalPreset int    `bson:"total_preset"`
+	TotalLike   int    `bson:"total_like"`
+}
+
+// LikedPreset LikedAt is the last time the user liked a tag of the preset.
+type LikedPreset struct {
+	PresetId string    `bson:"_id"`
+	LikedAt  time.Time `bson:"liked_at"`
+}
+
+type FindLikedPresetsResult struct {
+	Items []LikedPreset
+	Total int
+}
+
 type PresetTagRepository interface {
 	FindTagById(string) (*PresetTag, error)
 	FindTagsByPresetId(string) ([]PresetTag, error)
@@ -63,8 +104,19 @@ type PresetTagRepository interface {
 	BulkOperationTags(createInput CreateTagInput, createIds []string) error
 	DeleteTag(id string) error
 	DeleteTagsByPresetId(presetId string) error
-	LikeTag(LikeTagInput) error
-	UnLikeTag(LikeTagInput) error
+	UpdateTagsClassId(presetId string, classId int) error
+	SetTagsDeletedAt(presetId string, deletedAt *time.Time) error
+	MergeTags(from []string, to string) (int, error)
+	FindDistinctTags() ([]string, error)
+	// LikeTag and UnLikeTag are false when the user already liked, or did not like, the tag
+	LikeTag(LikeTagInput) (bool, error)
+	UnLikeTag(LikeTagInput) (bool, error)
+	FindLikedTagIds(userId string, tagIds []string) ([]string, error)
+	FindLikedPresets(userId string, skip, take int) (*FindLikedPresetsResult, error)
+	CountLikesByPublisher(PublisherStatsInput) ([]PublisherCount, error)
+	MigrateLikes() (int, error)
 	PartialSearchTags(i PartialSearchTagsInput, skip, limit int) (*PartialSearchTagsResult, error)
 	FindByPresetIds([]string) ([]PresetTag, error)
+	SuggestTags(SuggestTagsInput) ([]TagSuggestion, error)
+	FindLikesSince(t time.Time) ([]TagLikeEvent, error)
 }
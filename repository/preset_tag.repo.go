@@ -2,6 +2,7 @@ package repository
 
 import (
 	"context"
+	"regexp"
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson"
@@ -10,12 +11,16 @@ import (
 	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
-func NewPresetTagRepository(c *mongo.Collection) PresetTagRepository {
-	return presetTagRepo{c: c}
+// NewPresetTagRepository likes is the collection of PresetTagLike, the tags keep only the count.
+// presets is the preset collection, its total_like is kept as the sum of the likes of its tags.
+func NewPresetTagRepository(c *mongo.Collection, likes *mongo.Collection, presets *mongo.Collection) PresetTagRepository {
+	return presetTagRepo{c: c, likes: likes, presets: presets}
 }
 
 type presetTagRepo struct {
-	c *mongo.Collection
+	c       *mongo.Collection
+	likes   *mongo.Collection
+	presets *mongo.Collection
 }
 
 func (r presetTagRepo) BulkOperationTags(createsInput CreateTagInput, deleteIds []string) error {
@@ -27,22 +32,96 @@ func (r presetTagRepo) BulkOperationTags(createsInput CreateTagInput, deleteIds
 			Tag:         tag,
 			ClassId:     createsInput.ClassId,
 			PresetId:    createsInput.PresetId,
-			Likes:       []string{},
 			TotalLike:   0,
 			CreatedAt:   now,
 			UpdatedAt:   now,
 		}))
 	}
 
+	deleteObjIds := []primitive.ObjectID{}
 	for _, id := range deleteIds {
 		objId, err := primitive.ObjectIDFromHex(id)
 		if err != nil {
 			return err
 		}
 		bulks = append(bulks, mongo.NewDeleteOneModel().SetFilter(bson.M{"_id": objId}))
+		deleteObjIds = append(deleteObjIds, objId)
+	}
+	if len(bulks) == 0 {
+		return nil
+	}
+
+	deleted, err := r.findTagLikes(deleteObjIds)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.c.BulkWrite(context.Background(), bulks)
+	if err != nil {
+		return err
+	}
+
+	err = r.takeTagLikesFromPresets(deleted)
+	if err != nil {
+		return err
+	}
+
+	return r.deleteLikes(deleteObjIds)
+}
+
+// findTagLikes reads the preset and like count of tags about to be deleted.
+func (r presetTagRepo) findTagLikes(tagIds []primitive.ObjectID) ([]PresetTag, error) {
+	if len(tagIds) == 0 {
+		return []PresetTag{}, nil
+	}
+
+	cursor, err := r.c.Find(context.Background(), bson.M{"_id": bson.M{"$in": tagIds}}, options.Find().SetProjection(bson.M{
+		"preset_id":  1,
+		"total_like": 1,
+	}))
+	if err != nil {
+		return nil, err
+	}
+
+	tags := []PresetTag{}
+	err = cursor.All(context.Background(), &tags)
+	if err != nil {
+		return nil, err
+	}
+
+	return tags, nil
+}
+
+// takeTagLikesFromPresets removes the likes of deleted tags from the total_like of their presets.
+func (r presetTagRepo) takeTagLikesFromPresets(tags []PresetTag) error {
+	for _, tag := range tags {
+		err := r.incPresetTotalLike(tag.PresetId, -tag.TotalLike)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (r presetTagRepo) incPresetTotalLike(presetId string, n int) error {
+	if n == 0 {
+		return nil
+	}
+
+	_, err := r.presets.UpdateOne(context.Background(), bson.M{"id": presetId}, bson.M{
+		"$inc": bson.M{"total_like": n},
+	})
+
+	return err
+}
+
+func (r presetTagRepo) deleteLikes(tagIds []primitive.ObjectID) error {
+	if len(tagIds) == 0 {
+		return nil
 	}
 
-	_, err := r.c.BulkWrite(context.Background(), bulks)
+	_, err := r.likes.DeleteMany(context.Background(), bson.M{"tag_id": bson.M{"$in": tagIds}})
 
 	return err
 }
@@ -64,8 +143,115 @@ func (r presetTagRepo) FindTagsByPresetId(presetId string) ([]PresetTag, error)
 	return tags, nil
 }
 
+// DeleteTagsByPresetId also deletes the tags hidden with the preset in the trash.
 func (r presetTagRepo) DeleteTagsByPresetId(presetId string) error {
-	_, err := r.c.DeleteMany(context.Background(), PartialSearchTagsInput{PresetId: presetId})
+	tagIds, err := r.c.Distinct(context.Background(), "_id", bson.M{"preset_id": presetId})
+	if err != nil {
+		return err
+	}
+
+	_, err = r.c.DeleteMany(context.Background(), bson.M{"preset_id": presetId})
+	if err != nil {
+		return err
+	}
+
+	_, err = r.presets.UpdateOne(context.Background(), bson.M{"id": presetId}, bson.M{
+		"$set": bson.M{"total_like": 0},
+	})
+	if err != nil {
+		return err
+	}
+
+	objIds := []primitive.ObjectID{}
+	for _, v := range tagIds {
+		if objId, ok := v.(primitive.ObjectID); ok {
+			objIds = append(objIds, objId)
+		}
+	}
+
+	return r.deleteLikes(objIds)
+}
+
+// SetTagsDeletedAt hides the tags of a preset in the trash, nil brings them back.
+func (r presetTagRepo) SetTagsDeletedAt(presetId string, deletedAt *time.Time) error {
+	update := bson.M{"$unset": bson.M{"deleted_at": ""}}
+	if deletedAt != nil {
+		update = bson.M{"$set": bson.M{"deleted_at": *deletedAt}}
+	}
+
+	_, err := r.c.UpdateMany(context.Background(), bson.M{"preset_id": presetId}, update)
+
+	return err
+}
+
+// MergeTags rewrites the tags in from to the tag to, tags in the trash included.
+// A preset that already has the tag to keeps that one, the likes move to it unless the user liked both.
+func (r presetTagRepo) MergeTags(from []string, to string) (int, error) {
+	cursor, err := r.c.Find(context.Background(), bson.M{"tag": bson.M{"$in": from}})
+	if err != nil {
+		return 0, err
+	}
+
+	tags := []PresetTag{}
+	err = cursor.All(context.Background(), &tags)
+	if err != nil {
+		return 0, err
+	}
+
+	now := time.Now()
+	for _, tag := range tags {
+		objId, err := primitive.ObjectIDFromHex(tag.Id)
+		if err != nil {
+			return 0, err
+		}
+
+		_, err = r.c.UpdateByID(context.Background(), objId, bson.M{
+			"$set": bson.M{"tag": to, "updated_at": now},
+		})
+		if err == nil {
+			continue
+		}
+		if !mongo.IsDuplicateKeyError(err) {
+			return 0, err
+		}
+
+		var target PresetTag
+		err = r.c.FindOne(context.Background(), bson.M{"preset_id": tag.PresetId, "tag": to}).Decode(&target)
+		if err != nil {
+			return 0, err
+		}
+		targetId, err := primitive.ObjectIDFromHex(target.Id)
+		if err != nil {
+			return 0, err
+		}
+
+		err = r.moveLikes(objId, targetId)
+		if err != nil {
+			return 0, err
+		}
+
+		_, err = r.c.DeleteOne(context.Background(), bson.M{"_id": objId})
+		if err != nil {
+			return 0, err
+		}
+
+		// the moved likes were added to the preset again through the target tag
+		err = r.incPresetTotalLike(tag.PresetId, -tag.TotalLike)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	return len(tags), nil
+}
+
+func (r presetTagRepo) UpdateTagsClassId(presetId string, classId int) error {
+	_, err := r.c.UpdateMany(context.Background(), PartialSearchTagsInput{PresetId: presetId}, bson.M{
+		"$set": bson.M{
+			"class_id":   classId,
+			"updated_at": time.Now(),
+		},
+	})
 
 	return err
 }
@@ -77,7 +263,7 @@ func (r presetTagRepo) FindTagById(id string) (*PresetTag, error) {
 	}
 
 	var p PresetTag
-	err = r.c.FindOne(context.Background(), bson.M{"_id": objId}).Decode(&p)
+	err = r.c.FindOne(context.Background(), bson.M{"_id": objId, "deleted_at": nil}).Decode(&p)
 	if err != nil {
 		return nil, err
 	}
@@ -104,8 +290,32 @@ func (r presetTagRepo) FindByPresetIds(ids []string) ([]PresetTag, error) {
 	return tags, nil
 }
 
+// FindDistinctTags gives every tag in use, trash included.
+func (r presetTagRepo) FindDistinctTags() ([]string, error) {
+	values, err := r.c.Distinct(context.Background(), "tag", bson.M{})
+	if err != nil {
+		return nil, err
+	}
+
+	tags := []string{}
+	for _, v := range values {
+		if tag, ok := v.(string); ok {
+			tags = append(tags, tag)
+		}
+	}
+
+	return tags, nil
+}
+
 func (r presetTagRepo) PartialSearchTags(i PartialSearchTagsInput, skip, limit int) (*PartialSearchTagsResult, error) {
-	total, err := r.c.CountDocuments(context.Background(), i)
+	var filter interface{} = i
+	if i.Tag != "" && len(i.TagAliases) > 0 {
+		tags := append([]string{i.Tag}, i.TagAliases...)
+		i.Tag = ""
+		filter = bson.M{"$and": bson.A{i, bson.M{"tag": bson.M{"$in": tags}}}}
+	}
+
+	total, err := r.c.CountDocuments(context.Background(), filter)
 	if err != nil {
 		return nil, err
 	}
@@ -114,7 +324,7 @@ func (r presetTagRepo) PartialSearchTags(i PartialSearchTagsInput, skip, limit i
 		{Key: "total_like", Value: -1},
 		{Key: "created_at", Value: -1},
 	})
-	res, err := r.c.Find(context.Background(), i, fOpts)
+	res, err := r.c.Find(context.Background(), filter, fOpts)
 	if err != nil {
 		return nil, err
 	}
@@ -140,7 +350,6 @@ func (r presetTagRepo) CreateTags(i CreateTagInput) ([]string, error) {
 			Tag:         tag,
 			ClassId:     i.ClassId,
 			PresetId:    i.PresetId,
-			Likes:       []string{},
 			TotalLike:   0,
 			CreatedAt:   now,
 			UpdatedAt:   now,
@@ -166,45 +375,433 @@ func (r presetTagRepo) DeleteTag(id string) error {
 		return err
 	}
 
+	deleted, err := r.findTagLikes([]primitive.ObjectID{objId})
+	if err != nil {
+		return err
+	}
+
 	_, err = r.c.DeleteOne(context.Background(), bson.M{"_id": objId})
+	if err != nil {
+		return err
+	}
 
-	return err
+	err = r.takeTagLikesFromPresets(deleted)
+	if err != nil {
+		return err
+	}
+
+	return r.deleteLikes([]primitive.ObjectID{objId})
 }
 
-func (r presetTagRepo) LikeTag(i LikeTagInput) error {
-	objId, err := primitive.ObjectIDFromHex(i.Id)
+// moveLikes gives the likes of one tag to another, a user who liked both keeps one like.
+func (r presetTagRepo) moveLikes(from, to primitive.ObjectID) error {
+	cursor, err := r.likes.Find(context.Background(), bson.M{"tag_id": from})
 	if err != nil {
 		return err
 	}
 
-	_, err = r.c.UpdateByID(context.Background(), objId, bson.M{
-		"$addToSet": bson.M{
-			"likes": i.UserId,
-		},
-		"$set": PartialUpdateTagInput{
-			TotalLike: i.TotalLike,
-			UpdatedAt: time.Now(),
-		},
+	likes := []PresetTagLike{}
+	err = cursor.All(context.Background(), &likes)
+	if err != nil {
+		return err
+	}
+
+	moved := 0
+	for _, like := range likes {
+		_, err = r.likes.UpdateOne(context.Background(), bson.M{"tag_id": from, "user_id": like.UserId}, bson.M{
+			"$set": bson.M{"tag_id": to},
+		})
+		if err == nil {
+			moved++
+			continue
+		}
+		if !mongo.IsDuplicateKeyError(err) {
+			return err
+		}
+	}
+
+	err = r.deleteLikes([]primitive.ObjectID{from})
+	if err != nil {
+		return err
+	}
+
+	return r.incTotalLike(to, moved)
+}
+
+// incTotalLike counts the like on the tag and on its preset.
+func (r presetTagRepo) incTotalLike(id primitive.ObjectID, n int) error {
+	if n == 0 {
+		return nil
+	}
+
+	var tag PresetTag
+	err := r.c.FindOneAndUpdate(context.Background(), bson.M{"_id": id}, bson.M{
+		"$inc": bson.M{"total_like": n},
+		"$set": bson.M{"updated_at": time.Now()},
+	}, options.FindOneAndUpdate().SetProjection(bson.M{"preset_id": 1})).Decode(&tag)
+	if err == mongo.ErrNoDocuments {
+		// the tag was deleted meanwhile, its likes went with it
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	return r.incPresetTotalLike(tag.PresetId, n)
+}
+
+// LikeTag relies on the unique (tag_id, user_id) index, so concurrent likes are counted once.
+func (r presetTagRepo) LikeTag(i LikeTagInput) (bool, error) {
+	objId, err := primitive.ObjectIDFromHex(i.Id)
+	if err != nil {
+		return false, err
+	}
+
+	_, err = r.likes.InsertOne(context.Background(), PresetTagLike{
+		TagId:     objId,
+		UserId:    i.UserId,
+		CreatedAt: time.Now(),
 	})
+	if mongo.IsDuplicateKeyError(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
 
-	return err
+	return true, r.incTotalLike(objId, 1)
 }
 
-func (r presetTagRepo) UnLikeTag(i LikeTagInput) error {
+func (r presetTagRepo) UnLikeTag(i LikeTagInput) (bool, error) {
 	objId, err := primitive.ObjectIDFromHex(i.Id)
+	if err != nil {
+		return false, err
+	}
+
+	res, err := r.likes.DeleteOne(context.Background(), bson.M{"tag_id": objId, "user_id": i.UserId})
+	if err != nil {
+		return false, err
+	}
+	if res.DeletedCount == 0 {
+		return false, nil
+	}
+
+	return true, r.incTotalLike(objId, -1)
+}
+
+func (r presetTagRepo) FindLikedTagIds(userId string, tagIds []string) ([]string, error) {
+	objIds := []primitive.ObjectID{}
+	for _, id := range tagIds {
+		objId, err := primitive.ObjectIDFromHex(id)
+		if err != nil {
+			return nil, err
+		}
+		objIds = append(objIds, objId)
+	}
+
+	cursor, err := r.likes.Find(context.Background(), bson.M{
+		"user_id": userId,
+		"tag_id":  bson.M{"$in": objIds},
+	}, options.Find().SetProjection(bson.M{"tag_id": 1}))
+	if err != nil {
+		return nil, err
+	}
+
+	likes := []PresetTagLike{}
+	err = cursor.All(context.Background(), &likes)
+	if err != nil {
+		return nil, err
+	}
+
+	res := []string{}
+	for _, v := range likes {
+		res = append(res, v.TagId.Hex())
+	}
+
+	return res, nil
+}
+
+// FindLikedPresets pages the presets the user liked a tag of, the last liked first.
+func (r presetTagRepo) FindLikedPresets(userId string, skip, take int) (*FindLikedPresetsResult, error) {
+	cursor, err := r.likes.Aggregate(context.Background(), mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"user_id": userId}}},
+		{{Key: "$lookup", Value: bson.M{
+			"from":         r.c.Name(),
+			"localField":   "tag_id",
+			"foreignField": "_id",
+			"as":           "tag",
+		}}},
+		{{Key: "$unwind", Value: "$tag"}},
+		{{Key: "$match", Value: bson.M{"tag.deleted_at": nil}}},
+		{{Key: "$group", Value: bson.M{
+			"_id":      "$tag.preset_id",
+			"liked_at": bson.M{"$max": "$created_at"},
+		}}},
+		{{Key: "$facet", Value: bson.M{
+			"total": bson.A{bson.M{"$count": "count"}},
+			"items": bson.A{
+				bson.M{"$sort": bson.D{{Key: "liked_at", Value: -1}, {Key: "_id", Value: 1}}},
+				bson.M{"$skip": skip},
+				bson.M{"$limit": take},
+			},
+		}}},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var res []struct {
+		Total []struct {
+			Count int `bson:"count"`
+		} `bson:"total"`
+		Items []LikedPreset `bson:"items"`
+	}
+	err = cursor.All(context.Background(), &res)
+	if err != nil {
+		return nil, err
+	}
+
+	liked := FindLikedPresetsResult{Items: []LikedPreset{}}
+	if len(res) > 0 {
+		liked.Items = append(liked.Items, res[0].Items...)
+		if len(res[0].Total) > 0 {
+			liked.Total = res[0].Total[0].Count
+		}
+	}
+
+	return &liked, nil
+}
+
+// CountLikesByPublisher leaves out likes publishers gave to their own tags.
+func (r presetTagRepo) CountLikesByPublisher(i PublisherStatsInput) ([]PublisherCount, error) {
+	match := bson.M{}
+	if i.Since != nil {
+		match["created_at"] = bson.M{"$gte": *i.Since}
+	}
+
+	tagMatch := bson.M{
+		"tag.deleted_at": nil,
+		"$expr":          bson.M{"$ne": bson.A{"$tag.publisher_id", "$user_id"}},
+	}
+	if i.UserId != "" {
+		tagMatch["tag.publisher_id"] = i.UserId
+	}
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: match}},
+		{{Key: "$lookup", Value: bson.M{
+			"from":         r.c.Name(),
+			"localField":   "tag_id",
+			"foreignField": "_id",
+			"as":           "tag",
+		}}},
+		{{Key: "$unwind", Value: "$tag"}},
+		{{Key: "$match", Value: tagMatch}},
+	}
+
+	return aggregatePublisherCounts(r.likes, append(pipeline, groupByPublisher("$tag.publisher_id", "$tag.class_id")...))
+}
+
+// legacyTagLikes are the like arrays tags had before likes got their own collection.
+type legacyTagLikes struct {
+	Id        primitive.ObjectID `bson:"_id"`
+	PresetId  string             `bson:"preset_id"`
+	Likes     []string           `bson:"likes"`
+	CreatedAt time.Time          `bson:"created_at"`
+}
+
+// MigrateLikes moves the like arrays of tags into the likes collection and recounts total_like,
+// then recounts total_like of the presets of those tags and of presets saved before it existed.
+// The arrays had no like times, likes get the time of the tag. Running it again only touches tags that still have arrays.
+func (r presetTagRepo) MigrateLikes() (int, error) {
+	cursor, err := r.c.Find(context.Background(), bson.M{"likes": bson.M{"$exists": true}}, options.Find().SetProjection(bson.M{
+		"preset_id":  1,
+		"likes":      1,
+		"created_at": 1,
+	}))
+	if err != nil {
+		return 0, err
+	}
+	defer cursor.Close(context.Background())
+
+	total := 0
+	presetIds := []string{}
+	for cursor.Next(context.Background()) {
+		var tag legacyTagLikes
+		err = cursor.Decode(&tag)
+		if err != nil {
+			return total, err
+		}
+
+		bulks := []mongo.WriteModel{}
+		for _, userId := range tag.Likes {
+			bulks = append(bulks, mongo.NewUpdateOneModel().
+				SetFilter(bson.M{"tag_id": tag.Id, "user_id": userId}).
+				SetUpdate(bson.M{"$setOnInsert": PresetTagLike{TagId: tag.Id, UserId: userId, CreatedAt: tag.CreatedAt}}).
+				SetUpsert(true))
+		}
+		if len(bulks) > 0 {
+			_, err = r.likes.BulkWrite(context.Background(), bulks)
+			if err != nil {
+				return total, err
+			}
+		}
+
+		count, err := r.likes.CountDocuments(context.Background(), bson.M{"tag_id": tag.Id})
+		if err != nil {
+			return total, err
+		}
+
+		_, err = r.c.UpdateByID(context.Background(), tag.Id, bson.M{
+			"$set":   bson.M{"total_like": count},
+			"$unset": bson.M{"likes": ""},
+		})
+		if err != nil {
+			return total, err
+		}
+		presetIds = append(presetIds, tag.PresetId)
+		total++
+	}
+	if err = cursor.Err(); err != nil {
+		return total, err
+	}
+
+	return total, r.recountPresetLikes(bson.M{"$or": bson.A{
+		bson.M{"total_like": bson.M{"$exists": false}},
+		bson.M{"id": bson.M{"$in": presetIds}},
+	}})
+}
+
+// recountPresetLikes sets total_like of the presets matching filter to the likes of their tags, 500 presets at a time.
+func (r presetTagRepo) recountPresetLikes(filter bson.M) error {
+	values, err := r.presets.Distinct(context.Background(), "id", filter)
 	if err != nil {
 		return err
 	}
 
-	_, err = r.c.UpdateByID(context.Background(), objId, bson.M{
-		"$pull": bson.M{
-			"likes": i.UserId,
-		},
-		"$set": PartialUpdateTagInput{
-			TotalLike: i.TotalLike,
-			UpdatedAt: time.Now(),
+	ids := []string{}
+	for _, v := range values {
+		if id, ok := v.(string); ok {
+			ids = append(ids, id)
+		}
+	}
+
+	for start := 0; start < len(ids); start += 500 {
+		batch := ids[start:min(start+500, len(ids))]
+
+		cursor, err := r.c.Aggregate(context.Background(), mongo.Pipeline{
+			{{Key: "$match", Value: bson.M{"preset_id": bson.M{"$in": batch}}}},
+			{{Key: "$group", Value: bson.M{
+				"_id":   "$preset_id",
+				"total": bson.M{"$sum": "$total_like"},
+			}}},
+		})
+		if err != nil {
+			return err
+		}
+
+		var rows []struct {
+			PresetId string `bson:"_id"`
+			Total    int    `bson:"total"`
+		}
+		err = cursor.All(context.Background(), &rows)
+		if err != nil {
+			return err
+		}
+
+		totals := map[string]int{}
+		for _, v := range rows {
+			totals[v.PresetId] = v.Total
+		}
+
+		writes := []mongo.WriteModel{}
+		for _, id := range batch {
+			writes = append(writes, mongo.NewUpdateOneModel().
+				SetFilter(bson.M{"id": id}).
+				SetUpdate(bson.M{"$set": bson.M{"total_like": totals[id]}}))
+		}
+
+		_, err = r.presets.BulkWrite(context.Background(), writes, options.BulkWrite().SetOrdered(false))
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// SuggestTags ranks tags by the presets using them, then by their likes. The NoTag placeholder is on
+// every published preset and is never suggested.
+// Tags only live on published presets, so every tag outside the trash is a published use.
+func (r presetTagRepo) SuggestTags(i SuggestTagsInput) ([]TagSuggestion, error) {
+	match := bson.M{
+		"deleted_at": nil,
+		"tag":        bson.M{"$nin": append([]string{NoTag}, i.ExcludeTags...)},
+		"$or": bson.A{
+			bson.M{"tag": bson.M{"$regex": "^" + regexp.QuoteMeta(i.Prefix)}},
+			bson.M{"tag": bson.M{"$in": i.Tags}},
 		},
+	}
+	if i.ClassId != 0 {
+		match["class_id"] = i.ClassId
+	}
+
+	cursor, err := r.c.Aggregate(context.Background(), mongo.Pipeline{
+		{{Key: "$match", Value: match}},
+		{{Key: "$group", Value: bson.M{
+			"_id":          "$tag",
+			"total_preset": bson.M{"$sum": 1},
+			"total_like":   bson.M{"$sum": "$total_like"},
+		}}},
+		{{Key: "$sort", Value: bson.D{
+			{Key: "total_preset", Value: -1},
+			{Key: "total_like", Value: -1},
+			{Key: "_id", Value: 1},
+		}}},
+		{{Key: "$limit", Value: i.Limit}},
 	})
+	if err != nil {
+		return nil, err
+	}
 
-	return err
+	res := []TagSuggestion{}
+	err = cursor.All(context.Background(), &res)
+	if err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// FindLikesSince reads the likes given after t to tags outside the trash.
+func (r presetTagRepo) FindLikesSince(t time.Time) ([]TagLikeEvent, error) {
+	cursor, err := r.likes.Aggregate(context.Background(), mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"created_at": bson.M{"$gte": t}}}},
+		{{Key: "$lookup", Value: bson.M{
+			"from":         r.c.Name(),
+			"localField":   "tag_id",
+			"foreignField": "_id",
+			"as":           "tag",
+		}}},
+		{{Key: "$unwind", Value: "$tag"}},
+		{{Key: "$match", Value: bson.M{"tag.deleted_at": nil}}},
+		{{Key: "$project", Value: bson.M{
+			"_id":       0,
+			"preset_id": "$tag.preset_id",
+			"tag":       "$tag.tag",
+			"class_id":  "$tag.class_id",
+			"at":        "$created_at",
+		}}},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	res := []TagLikeEvent{}
+	err = cursor.All(context.Background(), &res)
+	if err != nil {
+		return nil, err
+	}
+
+	return res, nil
 }
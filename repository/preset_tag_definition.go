@@ -0,0 +1,35 @@
+package repository
+
+import (
+	"regexp"
+	"strings"
+	"time"
+)
+
+var tagSeparatorPattern = regexp.MustCompile(`[\s_-]+`)
+
+// PresetTagDefinition is a canonical tag of the registry, Synonyms are other slugs that mean the same tag.
+// A banned definition cannot be added to presets, nor can its synonyms.
+type PresetTagDefinition struct {
+	Slug      string    `bson:"slug" json:"slug"`
+	NameEn    string    `bson:"name_en" json:"nameEn"`
+	NameTh    string    `bson:"name_th" json:"nameTh"`
+	Synonyms  []string  `bson:"synonyms" json:"synonyms"`
+	Banned    bool      `bson:"banned" json:"banned"`
+	CreatedAt time.Time `bson:"created_at" json:"createdAt"`
+	UpdatedAt time.Time `bson:"updated_at" json:"updatedAt"`
+}
+
+// NormalizeTagSlug lowercases a tag and joins its words with "_", " MVP  boss" is "mvp_boss".
+func NormalizeTagSlug(tag string) string {
+	slug := tagSeparatorPattern.ReplaceAllString(strings.ToLower(strings.TrimSpace(tag)), "_")
+
+	return strings.Trim(slug, "_")
+}
+
+type PresetTagDefinitionRepository interface {
+	FindTagDefinitions() ([]PresetTagDefinition, error)
+	FindTagDefinitionBySlug(slug string) (*PresetTagDefinition, error)
+	UpsertTagDefinition(PresetTagDefinition) error
+	DeleteTagDefinitions(slugs []string) error
+}
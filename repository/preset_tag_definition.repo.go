@@ -0,0 +1,67 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+func NewPresetTagDefinitionRepository(c *mongo.Collection) PresetTagDefinitionRepository {
+	return presetTagDefinitionRepo{c: c}
+}
+
+type presetTagDefinitionRepo struct {
+	c *mongo.Collection
+}
+
+func (r presetTagDefinitionRepo) FindTagDefinitions() ([]PresetTagDefinition, error) {
+	cursor, err := r.c.Find(context.Background(), bson.M{}, options.Find().SetSort(bson.M{"slug": 1}))
+	if err != nil {
+		return nil, err
+	}
+
+	tags := []PresetTagDefinition{}
+	err = cursor.All(context.Background(), &tags)
+	if err != nil {
+		return nil, err
+	}
+
+	return tags, nil
+}
+
+func (r presetTagDefinitionRepo) FindTagDefinitionBySlug(slug string) (*PresetTagDefinition, error) {
+	var tag PresetTagDefinition
+	err := r.c.FindOne(context.Background(), bson.M{"slug": slug}).Decode(&tag)
+	if err != nil {
+		return nil, err
+	}
+
+	return &tag, nil
+}
+
+func (r presetTagDefinitionRepo) UpsertTagDefinition(t PresetTagDefinition) error {
+	now := time.Now()
+	_, err := r.c.UpdateOne(context.Background(), bson.M{"slug": t.Slug}, bson.M{
+		"$set": bson.M{
+			"name_en":    t.NameEn,
+			"name_th":    t.NameTh,
+			"synonyms":   t.Synonyms,
+			"banned":     t.Banned,
+			"updated_at": now,
+		},
+		"$setOnInsert": bson.M{
+			"created_at": now,
+		},
+	}, options.Update().SetUpsert(true))
+
+	return err
+}
+
+func (r presetTagDefinitionRepo) DeleteTagDefinitions(slugs []string) error {
+	_, err := r.c.DeleteMany(context.Background(), bson.M{"slug": bson.M{"$in": slugs}})
+
+	return err
+}
@@ -0,0 +1,78 @@
+package repository
+
+import "time"
+
+type TrendingWindow string
+
+var TrendingWindows = struct {
+	Day   TrendingWindow
+	Week  TrendingWindow
+	Month TrendingWindow
+}{
+	Day:   "day",
+	Week:  "week",
+	Month: "month",
+}
+
+func (w TrendingWindow) IsValid() bool {
+	switch w {
+	case TrendingWindows.Day, TrendingWindows.Week, TrendingWindows.Month:
+		return true
+	}
+
+	return false
+}
+
+func (w TrendingWindow) Duration() time.Duration {
+	switch w {
+	case TrendingWindows.Day:
+		return 24 * time.Hour
+	case TrendingWindows.Week:
+		return 7 * 24 * time.Hour
+	}
+
+	return 30 * 24 * time.Hour
+}
+
+type TrendingKind string
+
+var TrendingKinds = struct {
+	Preset TrendingKind
+	Tag    TrendingKind
+}{
+	Preset: "preset",
+	Tag:    "tag",
+}
+
+// PresetTrendingScore Key is the preset id or the tag, TotalLike counts the likes inside the window.
+// Tag scores are kept per class and with ClassId 0 for every class, a preset has one score
+// with the class it was published with at the refresh.
+type PresetTrendingScore struct {
+	Kind      TrendingKind   `bson:"kind"`
+	Key       string         `bson:"key"`
+	ClassId   int            `bson:"class_id"`
+	Window    TrendingWindow `bson:"window"`
+	Score     float64        `bson:"score"`
+	TotalLike int            `bson:"total_like"`
+	UpdatedAt time.Time      `bson:"updated_at"`
+}
+
+// FindTrendingScoresInput ClassId 0 finds preset scores of every class, preset scores are
+// only found while their preset is published.
+type FindTrendingScoresInput struct {
+	Kind    TrendingKind
+	Window  TrendingWindow
+	ClassId int
+	Skip    int
+	Take    int
+}
+
+type FindTrendingScoresResult struct {
+	Items []PresetTrendingScore
+	Total int
+}
+
+type PresetTrendingScoreRepository interface {
+	ReplaceScores(window TrendingWindow, scores []PresetTrendingScore, refreshedAt time.Time) error
+	FindScores(FindTrendingScoresInput) (*FindTrendingScoresResult, error)
+}
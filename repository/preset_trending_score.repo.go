@@ -0,0 +1,153 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// NewPresetTrendingScoreRepository presets is the preset collection, preset scores are only found
+// while their preset is published.
+func NewPresetTrendingScoreRepository(c *mongo.Collection, presets *mongo.Collection) PresetTrendingScoreRepository {
+	return presetTrendingScoreRepo{c: c, presets: presets}
+}
+
+type presetTrendingScoreRepo struct {
+	c       *mongo.Collection
+	presets *mongo.Collection
+}
+
+// ReplaceScores upserts the scores of the window by (kind, key, class_id, window) and then deletes
+// the ones older than refreshedAt, so readers never see an empty window and instances refreshing
+// at the same time write the same rows.
+func (r presetTrendingScoreRepo) ReplaceScores(window TrendingWindow, scores []PresetTrendingScore, refreshedAt time.Time) error {
+	writes := []mongo.WriteModel{}
+	flush := func() error {
+		if len(writes) == 0 {
+			return nil
+		}
+
+		_, err := r.c.BulkWrite(context.Background(), writes, options.BulkWrite().SetOrdered(false))
+		writes = []mongo.WriteModel{}
+
+		return err
+	}
+
+	for _, v := range scores {
+		writes = append(writes, mongo.NewReplaceOneModel().
+			SetFilter(bson.M{"kind": v.Kind, "key": v.Key, "class_id": v.ClassId, "window": v.Window}).
+			SetReplacement(v).
+			SetUpsert(true))
+
+		if len(writes) >= 500 {
+			err := flush()
+			if err != nil {
+				return err
+			}
+		}
+	}
+	err := flush()
+	if err != nil {
+		return err
+	}
+
+	_, err = r.c.DeleteMany(context.Background(), bson.M{
+		"window":     window,
+		"updated_at": bson.M{"$lt": refreshedAt},
+	})
+
+	return err
+}
+
+func (r presetTrendingScoreRepo) FindScores(i FindTrendingScoresInput) (*FindTrendingScoresResult, error) {
+	if i.Kind == TrendingKinds.Preset {
+		return r.findPresetScores(i)
+	}
+
+	filter := bson.M{"kind": i.Kind, "window": i.Window, "class_id": i.ClassId}
+
+	total, err := r.c.CountDocuments(context.Background(), filter)
+	if err != nil {
+		return nil, err
+	}
+
+	cursor, err := r.c.Find(context.Background(), filter, options.Find().SetSkip(int64(i.Skip)).SetLimit(int64(i.Take)).SetSort(bson.D{
+		{Key: "score", Value: -1},
+		{Key: "key", Value: 1},
+	}))
+	if err != nil {
+		return nil, err
+	}
+
+	items := []PresetTrendingScore{}
+	err = cursor.All(context.Background(), &items)
+	if err != nil {
+		return nil, err
+	}
+
+	return &FindTrendingScoresResult{
+		Items: items,
+		Total: int(total),
+	}, nil
+}
+
+// findPresetScores reads the class and the publish state from the preset itself, a preset unpublished or
+// moved to another class since the last refresh is neither listed nor counted.
+func (r presetTrendingScoreRepo) findPresetScores(i FindTrendingScoresInput) (*FindTrendingScoresResult, error) {
+	presetFilter := bson.M{"preset.is_published": true, "preset.deleted_at": nil}
+	if i.ClassId != 0 {
+		presetFilter["preset.published.class_id"] = i.ClassId
+	}
+
+	page := bson.A{
+		bson.M{"$sort": bson.D{{Key: "score", Value: -1}, {Key: "key", Value: 1}}},
+		bson.M{"$skip": i.Skip},
+	}
+	if i.Take > 0 {
+		page = append(page, bson.M{"$limit": i.Take})
+	}
+	page = append(page, bson.M{"$project": bson.M{"preset": 0}})
+
+	cursor, err := r.c.Aggregate(context.Background(), mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"kind": TrendingKinds.Preset, "window": i.Window}}},
+		{{Key: "$lookup", Value: bson.M{
+			"from":         r.presets.Name(),
+			"localField":   "key",
+			"foreignField": "id",
+			"as":           "preset",
+		}}},
+		{{Key: "$unwind", Value: "$preset"}},
+		{{Key: "$match", Value: presetFilter}},
+		{{Key: "$facet", Value: bson.M{
+			"items": page,
+			"total": bson.A{bson.M{"$count": "total"}},
+		}}},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var rows []struct {
+		Items []PresetTrendingScore `bson:"items"`
+		Total []struct {
+			Total int `bson:"total"`
+		} `bson:"total"`
+	}
+	err = cursor.All(context.Background(), &rows)
+	if err != nil {
+		return nil, err
+	}
+
+	res := FindTrendingScoresResult{Items: []PresetTrendingScore{}}
+	if len(rows) > 0 {
+		res.Items = append(res.Items, rows[0].Items...)
+		if len(rows[0].Total) > 0 {
+			res.Total = rows[0].Total[0].Total
+		}
+	}
+
+	return &res, nil
+}
@@ -0,0 +1,45 @@
+package repository
+
+import "time"
+
+// PublisherLeaderboardRow is what one publisher got inside a window, ClassId 0 sums every class.
+// Rows are recomputed on the trending schedule, see ReplaceLeaderboard.
+type PublisherLeaderboardRow struct {
+	Window         string    `bson:"window"`
+	ClassId        int       `bson:"class_id"`
+	UserId         string    `bson:"user_id"`
+	TotalLike      int       `bson:"total_like"`
+	TotalPublished int       `bson:"total_published"`
+	TotalFork      int       `bson:"total_fork"`
+	UpdatedAt      time.Time `bson:"updated_at"`
+}
+
+type LeaderboardSort string
+
+var LeaderboardSorts = struct {
+	Likes   LeaderboardSort
+	Forks   LeaderboardSort
+	Presets LeaderboardSort
+}{
+	Likes:   "likes",
+	Forks:   "forks",
+	Presets: "presets",
+}
+
+type FindLeaderboardInput struct {
+	Window  string
+	ClassId int
+	Sort    LeaderboardSort
+	Skip    int
+	Take    int
+}
+
+type FindLeaderboardResult struct {
+	Items []PublisherLeaderboardRow
+	Total int
+}
+
+type PublisherLeaderboardRepository interface {
+	ReplaceLeaderboard(window string, rows []PublisherLeaderboardRow, refreshedAt time.Time) error
+	FindLeaderboard(FindLeaderboardInput) (*FindLeaderboardResult, error)
+}
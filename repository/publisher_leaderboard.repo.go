@@ -0,0 +1,99 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+func NewPublisherLeaderboardRepository(c *mongo.Collection) PublisherLeaderboardRepository {
+	return publisherLeaderboardRepo{c: c}
+}
+
+type publisherLeaderboardRepo struct {
+	c *mongo.Collection
+}
+
+// ReplaceLeaderboard upserts the rows of the window by (window, class_id, user_id) and then deletes
+// the ones older than refreshedAt, like ReplaceScores.
+func (r publisherLeaderboardRepo) ReplaceLeaderboard(window string, rows []PublisherLeaderboardRow, refreshedAt time.Time) error {
+	writes := []mongo.WriteModel{}
+	flush := func() error {
+		if len(writes) == 0 {
+			return nil
+		}
+
+		_, err := r.c.BulkWrite(context.Background(), writes, options.BulkWrite().SetOrdered(false))
+		writes = []mongo.WriteModel{}
+
+		return err
+	}
+
+	for _, v := range rows {
+		writes = append(writes, mongo.NewReplaceOneModel().
+			SetFilter(bson.M{"window": v.Window, "class_id": v.ClassId, "user_id": v.UserId}).
+			SetReplacement(v).
+			SetUpsert(true))
+
+		if len(writes) >= 500 {
+			err := flush()
+			if err != nil {
+				return err
+			}
+		}
+	}
+	err := flush()
+	if err != nil {
+		return err
+	}
+
+	_, err = r.c.DeleteMany(context.Background(), bson.M{
+		"window":     window,
+		"updated_at": bson.M{"$lt": refreshedAt},
+	})
+
+	return err
+}
+
+// leaderboardSortKeys ties break on the next count, then on the user id.
+func leaderboardSortKeys(sortBy LeaderboardSort) bson.D {
+	switch sortBy {
+	case LeaderboardSorts.Forks:
+		return bson.D{{Key: "total_fork", Value: -1}, {Key: "total_like", Value: -1}, {Key: "user_id", Value: 1}}
+	case LeaderboardSorts.Presets:
+		return bson.D{{Key: "total_published", Value: -1}, {Key: "total_like", Value: -1}, {Key: "user_id", Value: 1}}
+	}
+
+	return bson.D{{Key: "total_like", Value: -1}, {Key: "total_fork", Value: -1}, {Key: "user_id", Value: 1}}
+}
+
+func (r publisherLeaderboardRepo) FindLeaderboard(i FindLeaderboardInput) (*FindLeaderboardResult, error) {
+	filter := bson.M{"window": i.Window, "class_id": i.ClassId}
+
+	total, err := r.c.CountDocuments(context.Background(), filter)
+	if err != nil {
+		return nil, err
+	}
+
+	cursor, err := r.c.Find(context.Background(), filter, options.Find().
+		SetSkip(int64(i.Skip)).
+		SetLimit(int64(i.Take)).
+		SetSort(leaderboardSortKeys(i.Sort)))
+	if err != nil {
+		return nil, err
+	}
+
+	items := []PublisherLeaderboardRow{}
+	err = cursor.All(context.Background(), &items)
+	if err != nil {
+		return nil, err
+	}
+
+	return &FindLeaderboardResult{
+		Items: items,
+		Total: int(total),
+	}, nil
+}
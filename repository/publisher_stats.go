@@ -0,0 +1,53 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// PublisherStatsInput Since nil counts from the start, UserId "" counts every publisher.
+type PublisherStatsInput struct {
+	Since  *time.Time
+	UserId string
+}
+
+// PublisherCount is what one publisher got in one class.
+type PublisherCount struct {
+	UserId  string `bson:"user_id"`
+	ClassId int    `bson:"class_id"`
+	Total   int    `bson:"total"`
+}
+
+// groupByPublisher counts the documents per publisher and class, userField and classField are expressions.
+func groupByPublisher(userField, classField interface{}) mongo.Pipeline {
+	return mongo.Pipeline{
+		{{Key: "$group", Value: bson.M{
+			"_id":   bson.M{"user_id": userField, "class_id": classField},
+			"total": bson.M{"$sum": 1},
+		}}},
+		{{Key: "$project", Value: bson.M{
+			"_id":      0,
+			"user_id":  "$_id.user_id",
+			"class_id": "$_id.class_id",
+			"total":    1,
+		}}},
+	}
+}
+
+func aggregatePublisherCounts(c *mongo.Collection, pipeline mongo.Pipeline) ([]PublisherCount, error) {
+	cursor, err := c.Aggregate(context.Background(), pipeline)
+	if err != nil {
+		return nil, err
+	}
+
+	res := []PublisherCount{}
+	err = cursor.All(context.Background(), &res)
+	if err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
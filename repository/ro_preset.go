@@ -186,6 +186,83 @@ type RoPreset struct {
 	PublishName string      `bson:"publish_name" json:"publishName"`
 	IsPublished bool        `bson:"is_published" json:"isPublished"`
 	PublishedAt time.Time   `bson:"published_at" json:"publishedAt"`
+
+	ForkedFrom       string `bson:"forked_from,omitempty" json:"forkedFrom,omitempty"`
+	ForkedFromUserId string `bson:"forked_from_user_id,omitempty" json:"-"`
+	RootPresetId     string `bson:"root_preset_id,omitempty" json:"rootPresetId,omitempty"`
+	ForkCount        int    `bson:"fork_count" json:"forkCount"`
+
+	// TotalLike is the sum of the likes on its tags, kept in step by the tag repository
+	TotalLike int `bson:"total_like" json:"totalLike"`
+
+	// Version goes up on every write to the preset, presets saved before it existed are 0
+	Version int `bson:"version" json:"version"`
+
+	// StatOverspent marks a model that uses more stat points than its level gives
+	StatOverspent bool `bson:"stat_overspent" json:"statOverspent"`
+
+	// EquippedItems is derived from Model on every save
+	EquippedItems []PresetEquippedItem `bson:"equipped_items" json:"-"`
+	// ItemOptions are the rawOptionTxts of Model that could be parsed, the rest is in UnknownItemOptions
+	ItemOptions        []PresetItemOption `bson:"item_options" json:"itemOptions"`
+	UnknownItemOptions []string           `bson:"unknown_item_options" json:"unknownItemOptions"`
+	SchemaVersion      int                `bson:"schema_version" json:"schemaVersion"`
+	FolderId           string             `bson:"folder_id,omitempty" json:"folderId,omitempty"`
+
+	// Published is what everyone else sees, the owner keeps editing Model as a draft
+	Published *PublishedPreset `bson:"published,omitempty" json:"-"`
+	// DraftChanged is set when Model is saved and cleared when it is published
+	DraftChanged bool `bson:"draft_changed" json:"-"`
+
+	// DeletedAt is set while the preset is in the trash
+	DeletedAt *time.Time `bson:"deleted_at,omitempty" json:"deletedAt,omitempty"`
+}
+
+type PublishedPreset struct {
+	Version       int                  `bson:"version"`
+	ClassId       int                  `bson:"class_id"`
+	Model         PresetModel          `bson:"model"`
+	SchemaVersion int                  `bson:"schema_version"`
+	EquippedItems []PresetEquippedItem `bson:"equipped_items"`
+	ItemOptions   []PresetItemOption   `bson:"item_options"`
+	PublishedAt   time.Time            `bson:"published_at"`
+}
+
+func NewPublishedPreset(version int, m PresetModel) PublishedPreset {
+	itemOptions, _ := m.ItemOptions()
+
+	return PublishedPreset{
+		Version:       version,
+		ClassId:       m.Class,
+		Model:         m,
+		SchemaVersion: PresetSchemaVersion,
+		EquippedItems: m.EquippedItems(),
+		ItemOptions:   itemOptions,
+		PublishedAt:   time.Now(),
+	}
+}
+
+// legacyPublishedSnapshot is the published copy of a preset published before drafts existed,
+// its model was frozen while published.
+func (p RoPreset) legacyPublishedSnapshot() PublishedPreset {
+	published := NewPublishedPreset(1, p.Model)
+	published.PublishedAt = p.PublishedAt
+
+	return published
+}
+
+// UsePublishedSnapshot replaces the draft with the published copy.
+// Presets published before drafts existed have no copy, their model was frozen while published.
+func (p *RoPreset) UsePublishedSnapshot() {
+	if p.Published == nil {
+		return
+	}
+
+	p.Model = p.Published.Model
+	p.ClassId = p.Published.ClassId
+	p.SchemaVersion = p.Published.SchemaVersion
+	// draft saves must not show through updated_at either
+	p.UpdatedAt = p.Published.PublishedAt
 }
 
 func (i *PresetModel) Validate() error {
@@ -204,6 +281,12 @@ type CreatePresetInput struct {
 	UserName string      `bson:"user_name" json:"userName"`
 	Label    string      `bson:"label" json:"label"`
 	Model    PresetModel `bson:"model" json:"model"`
+	Role     string      `bson:"-" json:"-"`
+
+	ForkedFrom       string `bson:"forked_from,omitempty" json:"-"`
+	ForkedFromUserId string `bson:"forked_from_user_id,omitempty" json:"-"`
+	RootPresetId     string `bson:"root_preset_id,omitempty" json:"-"`
+	StatOverspent    bool   `bson:"-" json:"-"`
 }
 
 func (i *CreatePresetInput) Validate() error {
@@ -228,6 +311,19 @@ type UpdatePresetInput struct {
 	PublishName string       `bson:"publish_name,omitempty" json:"publishName"`
 	IsPublished bool         `bson:"is_published,omitempty" json:"isPublished"`
 	PublishedAt time.Time    `bson:"published_at,omitempty" json:"publishedAt"`
+
+	StatOverspent *bool                 `bson:"stat_overspent,omitempty" json:"-"`
+	EquippedItems *[]PresetEquippedItem `bson:"equipped_items,omitempty" json:"-"`
+	ItemOptions   *[]PresetItemOption   `bson:"item_options,omitempty" json:"-"`
+	// UnknownItemOptions is set with ItemOptions
+	UnknownItemOptions *[]string        `bson:"unknown_item_options,omitempty" json:"-"`
+	SchemaVersion      int              `bson:"schema_version,omitempty" json:"-"`
+	Published          *PublishedPreset `bson:"published,omitempty" json:"-"`
+	// DraftChanged is set by UpdatePreset from Model and Published
+	DraftChanged *bool `bson:"draft_changed,omitempty" json:"-"`
+
+	// Version is the version the change was made against, nil writes without a check
+	Version *int `bson:"-" json:"version"`
 }
 
 type UnPublishPresetInput struct {
@@ -240,23 +336,36 @@ type UpdateTagsInput struct {
 	UpdatedAt time.Time `bson:"updated_at,omitempty"`
 }
 
+type BulkCreatePresetData struct {
+	Label         string      `bson:"label" json:"label"`
+	Model         PresetModel `bson:"model" json:"model"`
+	StatOverspent bool        `bson:"-" json:"-"`
+}
+
 type BulkCreatePresetInput struct {
-	UserId   string `bson:"user_id" json:"userId"`
-	UserName string `bson:"user_name" json:"userName"`
-	BulkData []struct {
-		Label string      `bson:"label" json:"label"`
-		Model PresetModel `bson:"model" json:"model"`
-	} `json:"bulkData"`
+	UserId   string                 `bson:"user_id" json:"userId"`
+	UserName string                 `bson:"user_name" json:"userName"`
+	Role     string                 `bson:"-" json:"-"`
+	BulkData []BulkCreatePresetData `json:"bulkData"`
 }
 
 type PartialSearchRoPresetInput struct {
-	Id           *string `bson:"id,omitempty"`
-	UserId       *string `bson:"user_id,omitempty"`
-	ClassId      *int    `bson:"class_id,omitempty"`
-	Label        *string `bson:"label,omitempty"`
-	Skip         *int
-	Take         *int
-	InCludeModel bool
+	Id          *string `bson:"id,omitempty"`
+	UserId      *string `bson:"user_id,omitempty"`
+	ClassId     *int    `bson:"class_id,omitempty"`
+	Label       *string `bson:"label,omitempty"`
+	IsPublished *bool   `bson:"is_published,omitempty"`
+	// PublishedClassId is the class of the published copy
+	PublishedClassId *int `bson:"published.class_id,omitempty"`
+	// FolderId "" only finds presets outside of any folder
+	FolderId *string `bson:"folder_id,omitempty"`
+	// LabelContains matches part of the label, case insensitive
+	LabelContains *string `bson:"-"`
+	// SortByPublished lists the latest published first instead of the latest saved
+	SortByPublished bool `bson:"-"`
+	Skip            *int
+	Take            *int
+	InCludeModel    bool
 }
 
 type PartialSearchRoPresetForUpdateInput struct {
@@ -266,8 +375,18 @@ type PartialSearchRoPresetForUpdateInput struct {
 	Label   string `bson:"label,omitempty"`
 }
 
+// IdSearchInput leaves out presets in the trash, DeletedAt is always nil.
 type IdSearchInput struct {
-	Id string `bson:"id"`
+	Id        string     `bson:"id"`
+	DeletedAt *time.Time `bson:"deleted_at"`
+}
+
+// ItemOptionTextSample is a rawOptionTxts text with how often and at which indexes presets have it.
+type ItemOptionTextSample struct {
+	Text     string `bson:"_id" json:"text"`
+	TotalUse int    `bson:"total_use" json:"totalUse"`
+	Indexes  []int  `bson:"indexes" json:"indexes"`
+	Parsed   bool   `bson:"-" json:"parsed"`
 }
 
 type PartialSearchRoPresetResult struct {
@@ -280,18 +399,111 @@ type FindPresetByIdInput struct {
 	InCludeModel bool
 }
 
+type PresetItemSearchSort string
+
+var PresetItemSearchSorts = struct {
+	Likes  PresetItemSearchSort
+	Latest PresetItemSearchSort
+}{
+	Likes:  "likes",
+	Latest: "latest",
+}
+
+type SearchPresetsByItemInput struct {
+	ItemId  int
+	ClassId *int
+	Slot    *string
+	Skill   *string
+	Sort    PresetItemSearchSort
+	Skip    int
+	Take    int
+}
+
+// SearchPresetsByItemOptionInput MinValue only finds options of at least that value.
+type SearchPresetsByItemOptionInput struct {
+	Option   string
+	Slot     *string
+	MinValue *int
+	ClassId  *int
+	Skill    *string
+	Sort     PresetItemSearchSort
+	Skip     int
+	Take     int
+}
+
 type PresetListSorting struct {
 	UpdatedAt int `bson:"updated_at"`
 }
 
+type PresetListSort string
+
+var PresetListSorts = struct {
+	UpdatedDesc PresetListSort
+	UpdatedAsc  PresetListSort
+	CreatedDesc PresetListSort
+	CreatedAsc  PresetListSort
+}{
+	UpdatedDesc: "-updatedAt",
+	UpdatedAsc:  "updatedAt",
+	CreatedDesc: "-createdAt",
+	CreatedAsc:  "createdAt",
+}
+
+func (s PresetListSort) IsValid() bool {
+	switch s {
+	case PresetListSorts.UpdatedDesc, PresetListSorts.UpdatedAsc, PresetListSorts.CreatedDesc, PresetListSorts.CreatedAsc:
+		return true
+	}
+
+	return false
+}
+
+// PresetListCursor is the sort value and id of the last preset of the previous page.
+type PresetListCursor struct {
+	At time.Time
+	Id string
+}
+
+// CursorSearchRoPresetInput reads presets after After in Sort order, Skip and Take of Filter are not used.
+type CursorSearchRoPresetInput struct {
+	Filter PartialSearchRoPresetInput
+	Sort   PresetListSort
+	After  *PresetListCursor
+	Limit  int
+}
+
 type RoPresetRepository interface {
 	FindPresetById(FindPresetByIdInput) (*RoPreset, error)
 	FindPresetByIds([]string) ([]RoPreset, error)
 	PartialSearchPresets(PartialSearchRoPresetInput) (*PartialSearchRoPresetResult, error)
+	CursorSearchPresets(CursorSearchRoPresetInput) ([]RoPreset, error)
+	CountPresetsByUserId(userId string) (int, error)
 	CreatePreset(CreatePresetInput) (*RoPreset, error)
 	CreatePresets(BulkCreatePresetInput) ([]RoPreset, error)
 	UpdatePreset(id string, i UpdatePresetInput) error
 	UpdateUserName(userId, userName string) error
 	UnpublishedPreset(id string) error
+	IncreaseForkCount(id string) error
+	FindForksByRootId(rootId string) ([]RoPreset, error)
+	CountPublishedByUser(PublisherStatsInput) ([]PublisherCount, error)
+	CountForksBySourceUser(PublisherStatsInput) ([]PublisherCount, error)
+	SearchPublishedPresetsByItem(SearchPresetsByItemInput) (*PartialSearchRoPresetResult, error)
+	SearchPublishedPresetsByItemOption(SearchPresetsByItemOptionInput) (*PartialSearchRoPresetResult, error)
+	RebuildDerivedFields() (int, error)
+	MigrateSchemaVersion() (int, error)
+	MovePresetsToFolder(userId string, presetIds []string, folderId string) (int, error)
+	RemovePresetsFromFolder(folderId string) error
+	CountFolderPresetsOfOtherClass(folderId string, classId int) (int, error)
+	RemoveOtherClassPresetsFromFolder(folderId string, classId int) error
+	CountPresetsByFolder(userId string) (map[string]int, error)
+	SoftDeletePresetById(id string, version *int) (int, error)
+	RestorePresetById(id string) (int, error)
+	FindDeletedPresetById(id string) (*RoPreset, error)
+	FindDeletedPresetsByUserId(userId string) ([]RoPreset, error)
+	FindPresetIdsDeletedBefore(t time.Time) ([]string, error)
+	FindPublishedPresetIdsByUserId(userId string) ([]string, error)
+	BackfillPublishedSnapshot(id string) error
+	BackfillPublishedSnapshots() (int, error)
+	SampleItemOptionTexts(take int) ([]ItemOptionTextSample, error)
 	DeletePresetById(string) (*int, error)
 }
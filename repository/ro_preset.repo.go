@@ -2,6 +2,8 @@ package repository
 
 import (
 	"context"
+	"regexp"
+	"ro-backend/appError"
 	"time"
 
 	"github.com/google/uuid"
@@ -30,21 +32,62 @@ func (r roPresetRepo) UpdateUserName(userId string, userName string) error {
 	return err
 }
 
+// UnpublishedPreset also matches presets in the trash, so moderation can take those down too.
 func (r roPresetRepo) UnpublishedPreset(id string) error {
-	_, err := r.collection.UpdateOne(context.Background(), IdSearchInput{Id: id}, bson.M{
+	_, err := r.collection.UpdateOne(context.Background(), bson.M{"id": id}, bson.M{
 		"$set": UnPublishPresetInput{
 			IsPublished: false,
 		},
+		"$unset": bson.M{
+			"published": "",
+		},
+		"$inc": bson.M{
+			"version": 1,
+		},
+	})
+
+	return err
+}
+
+func (r roPresetRepo) IncreaseForkCount(id string) error {
+	_, err := r.collection.UpdateOne(context.Background(), IdSearchInput{Id: id}, bson.M{
+		"$inc": bson.M{
+			"fork_count": 1,
+		},
 	})
 
 	return err
 }
 
+func (r roPresetRepo) FindForksByRootId(rootId string) ([]RoPreset, error) {
+	cursor, err := r.collection.Find(context.Background(), bson.M{
+		"root_preset_id": rootId,
+		"deleted_at":     nil,
+	}, options.Find().SetProjection(bson.M{
+		"model":           0,
+		"published.model": 0,
+	}).SetSort(bson.M{
+		"created_at": 1,
+	}))
+	if err != nil {
+		return nil, err
+	}
+
+	presets := []RoPreset{}
+	err = cursor.All(context.Background(), &presets)
+	if err != nil {
+		return nil, err
+	}
+
+	return presets, nil
+}
+
 func (r roPresetRepo) FindPresetByIds(ids []string) ([]RoPreset, error) {
 	cs, err := r.collection.Find(context.Background(), bson.M{
 		"id": bson.M{
 			"$in": ids,
 		},
+		"deleted_at": nil,
 	})
 	if err != nil {
 		return nil, err
@@ -55,21 +98,15 @@ func (r roPresetRepo) FindPresetByIds(ids []string) ([]RoPreset, error) {
 	if err != nil {
 		return nil, err
 	}
+	for i := range presets {
+		presets[i].upgradeModel()
+	}
 
 	return presets, nil
 }
 
 func (r roPresetRepo) PartialSearchPresets(i PartialSearchRoPresetInput) (*PartialSearchRoPresetResult, error) {
-	filter := bson.M{}
-	if i.ClassId != nil {
-		filter["class_id"] = *i.ClassId
-	}
-	if i.Id != nil {
-		filter["id"] = *i.Id
-	}
-	if i.UserId != nil {
-		filter["user_id"] = *i.UserId
-	}
+	filter := presetSearchFilter(i)
 
 	total, err := r.collection.CountDocuments(context.Background(), filter)
 	if err != nil {
@@ -89,17 +126,23 @@ func (r roPresetRepo) PartialSearchPresets(i PartialSearchRoPresetInput) (*Parti
 	var opt = options.FindOptions{}
 	if !i.InCludeModel {
 		opt.Projection = bson.M{
-			"model": 0,
+			"model":           0,
+			"published.model": 0,
 		}
 	}
 
+	var sort interface{} = PresetListSorting{
+		UpdatedAt: -1,
+	}
+	if i.SortByPublished {
+		sort = bson.D{{Key: "published_at", Value: -1}}
+	}
+
 	cursor, err := r.collection.Find(context.Background(), filter, &options.FindOptions{
 		Projection: opt.Projection,
 		Skip:       &skip,
 		Limit:      &take,
-		Sort: PresetListSorting{
-			UpdatedAt: -1,
-		},
+		Sort:       sort,
 	})
 	if err != nil {
 		return nil, err
@@ -110,6 +153,11 @@ func (r roPresetRepo) PartialSearchPresets(i PartialSearchRoPresetInput) (*Parti
 	if err != nil {
 		return nil, err
 	}
+	if i.InCludeModel {
+		for j := range items {
+			items[j].upgradeModel()
+		}
+	}
 
 	return &PartialSearchRoPresetResult{
 		Items: items,
@@ -117,8 +165,106 @@ func (r roPresetRepo) PartialSearchPresets(i PartialSearchRoPresetInput) (*Parti
 	}, nil
 }
 
+// CursorSearchPresets orders by the sort field and then id, so presets saved at the same time are never skipped.
+func (r roPresetRepo) CursorSearchPresets(i CursorSearchRoPresetInput) ([]RoPreset, error) {
+	field, direction := "updated_at", -1
+	switch i.Sort {
+	case PresetListSorts.UpdatedAsc:
+		direction = 1
+	case PresetListSorts.CreatedDesc:
+		field = "created_at"
+	case PresetListSorts.CreatedAsc:
+		field, direction = "created_at", 1
+	}
+
+	filter := presetSearchFilter(i.Filter)
+	if i.After != nil {
+		cmp := "$lt"
+		if direction == 1 {
+			cmp = "$gt"
+		}
+		filter["$or"] = bson.A{
+			bson.M{field: bson.M{cmp: i.After.At}},
+			bson.M{field: i.After.At, "id": bson.M{cmp: i.After.Id}},
+		}
+	}
+
+	opt := options.Find().SetSort(bson.D{
+		{Key: field, Value: direction},
+		{Key: "id", Value: direction},
+	}).SetLimit(int64(i.Limit))
+	if !i.Filter.InCludeModel {
+		opt.SetProjection(bson.M{
+			"model":           0,
+			"published.model": 0,
+		})
+	}
+
+	cursor, err := r.collection.Find(context.Background(), filter, opt)
+	if err != nil {
+		return nil, err
+	}
+
+	items := []RoPreset{}
+	err = cursor.All(context.Background(), &items)
+	if err != nil {
+		return nil, err
+	}
+	if i.Filter.InCludeModel {
+		for j := range items {
+			items[j].upgradeModel()
+		}
+	}
+
+	return items, nil
+}
+
+func presetSearchFilter(i PartialSearchRoPresetInput) bson.M {
+	filter := bson.M{"deleted_at": nil}
+	if i.ClassId != nil {
+		filter["class_id"] = *i.ClassId
+	}
+	if i.Id != nil {
+		filter["id"] = *i.Id
+	}
+	if i.UserId != nil {
+		filter["user_id"] = *i.UserId
+	}
+	if i.IsPublished != nil {
+		filter["is_published"] = *i.IsPublished
+	}
+	if i.PublishedClassId != nil {
+		filter["published.class_id"] = *i.PublishedClassId
+	}
+	if i.FolderId != nil {
+		if *i.FolderId == "" {
+			filter["folder_id"] = nil
+		} else {
+			filter["folder_id"] = *i.FolderId
+		}
+	}
+	if i.LabelContains != nil && *i.LabelContains != "" {
+		filter["label"] = bson.M{"$regex": regexp.QuoteMeta(*i.LabelContains), "$options": "i"}
+	}
+
+	return filter
+}
+
+func (r roPresetRepo) CountPresetsByUserId(userId string) (int, error) {
+	total, err := r.collection.CountDocuments(context.Background(), bson.M{
+		"user_id":    userId,
+		"deleted_at": nil,
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return int(total), nil
+}
+
+// DeletePresetById removes the preset for good, trash included.
 func (r roPresetRepo) DeletePresetById(id string) (*int, error) {
-	res, err := r.collection.DeleteOne(context.Background(), IdSearchInput{Id: id})
+	res, err := r.collection.DeleteOne(context.Background(), bson.M{"id": id})
 	if err != nil {
 		return nil, err
 	}
@@ -130,25 +276,40 @@ func (r roPresetRepo) DeletePresetById(id string) (*int, error) {
 
 func (r roPresetRepo) CreatePreset(i CreatePresetInput) (*RoPreset, error) {
 	id := uuid.NewString()
+	itemOptions, unknownItemOptions := i.Model.ItemOptions()
 	_, err := r.collection.InsertOne(context.Background(), RoPreset{
-		Id:        id,
-		UserId:    i.UserId,
-		Label:     i.Label,
-		Model:     i.Model,
-		ClassId:   i.Model.Class,
-		UserName:  i.UserName,
-		CreatedAt: time.Now(),
-		UpdatedAt: time.Now(),
+		Id:                 id,
+		UserId:             i.UserId,
+		Label:              i.Label,
+		Model:              i.Model,
+		ClassId:            i.Model.Class,
+		UserName:           i.UserName,
+		CreatedAt:          time.Now(),
+		UpdatedAt:          time.Now(),
+		ForkedFrom:         i.ForkedFrom,
+		ForkedFromUserId:   i.ForkedFromUserId,
+		RootPresetId:       i.RootPresetId,
+		StatOverspent:      i.StatOverspent,
+		EquippedItems:      i.Model.EquippedItems(),
+		ItemOptions:        itemOptions,
+		UnknownItemOptions: unknownItemOptions,
+		SchemaVersion:      PresetSchemaVersion,
 	})
 	if err != nil {
 		return nil, err
 	}
 
 	return &RoPreset{
-		Id:     id,
-		UserId: i.UserId,
-		Label:  i.Label,
-		Model:  i.Model,
+		Id:                 id,
+		UserId:             i.UserId,
+		Label:              i.Label,
+		Model:              i.Model,
+		ForkedFrom:         i.ForkedFrom,
+		RootPresetId:       i.RootPresetId,
+		StatOverspent:      i.StatOverspent,
+		ItemOptions:        itemOptions,
+		UnknownItemOptions: unknownItemOptions,
+		SchemaVersion:      PresetSchemaVersion,
 	}, nil
 }
 
@@ -157,15 +318,21 @@ func (r roPresetRepo) CreatePresets(ip BulkCreatePresetInput) ([]RoPreset, error
 	now := time.Now()
 	for i := 0; i < len(ip.BulkData); i++ {
 		var cur = ip.BulkData[i]
+		itemOptions, unknownItemOptions := cur.Model.ItemOptions()
 		var p = RoPreset{
-			Id:        uuid.NewString(),
-			UserId:    ip.UserId,
-			Label:     cur.Label,
-			Model:     cur.Model,
-			ClassId:   cur.Model.Class,
-			UserName:  ip.UserName,
-			CreatedAt: now,
-			UpdatedAt: now,
+			Id:                 uuid.NewString(),
+			UserId:             ip.UserId,
+			Label:              cur.Label,
+			Model:              cur.Model,
+			ClassId:            cur.Model.Class,
+			UserName:           ip.UserName,
+			CreatedAt:          now,
+			UpdatedAt:          now,
+			StatOverspent:      cur.StatOverspent,
+			EquippedItems:      cur.Model.EquippedItems(),
+			ItemOptions:        itemOptions,
+			UnknownItemOptions: unknownItemOptions,
+			SchemaVersion:      PresetSchemaVersion,
 		}
 		models = append(models, p)
 	}
@@ -198,23 +365,70 @@ func (r roPresetRepo) UpdatePreset(id string, i UpdatePresetInput) error {
 
 	if i.Model != nil {
 		i.ClassId = i.Model.Class
+		equippedItems := i.Model.EquippedItems()
+		i.EquippedItems = &equippedItems
+		itemOptions, unknownItemOptions := i.Model.ItemOptions()
+		i.ItemOptions = &itemOptions
+		i.UnknownItemOptions = &unknownItemOptions
+		i.SchemaVersion = PresetSchemaVersion
+	}
+	if i.Model != nil || i.Published != nil {
+		// a publish freezes the model, any later save is a change nobody else sees yet
+		draftChanged := i.Published == nil
+		i.DraftChanged = &draftChanged
 	}
 
-	_, err := r.collection.UpdateOne(context.Background(), IdSearchInput{Id: id}, bson.M{
+	res, err := r.collection.UpdateOne(context.Background(), r.versionFilter(id, i.Version), bson.M{
 		"$set": i,
+		"$inc": bson.M{
+			"version": 1,
+		},
 	})
 	if err != nil {
 		return err
 	}
+	if res.MatchedCount == 0 && i.Version != nil {
+		return r.versionConflict(id)
+	}
 
 	return nil
 }
 
+// versionFilter matches the preset only at version, nil matches any version.
+func (r roPresetRepo) versionFilter(id string, version *int) bson.M {
+	filter := bson.M{"id": id, "deleted_at": nil}
+	if version == nil {
+		return filter
+	}
+
+	if *version == 0 {
+		filter["version"] = bson.M{"$in": bson.A{0, nil}}
+	} else {
+		filter["version"] = *version
+	}
+
+	return filter
+}
+
+// versionConflict tells the version a preset is at after a write against another version matched nothing.
+func (r roPresetRepo) versionConflict(id string) error {
+	var data RoPreset
+	err := r.collection.FindOne(context.Background(), IdSearchInput{Id: id}, options.FindOne().SetProjection(bson.M{
+		"version": 1,
+	})).Decode(&data)
+	if err != nil {
+		return err
+	}
+
+	return &appError.VersionConflictError{CurrentVersion: data.Version}
+}
+
 func (r roPresetRepo) FindPresetById(i FindPresetByIdInput) (*RoPreset, error) {
 	var opt = options.FindOneOptions{}
 	if !i.InCludeModel {
 		opt.Projection = bson.M{
-			"model": 0,
+			"model":           0,
+			"published.model": 0,
 		}
 	}
 
@@ -223,6 +437,498 @@ func (r roPresetRepo) FindPresetById(i FindPresetByIdInput) (*RoPreset, error) {
 	if err != nil {
 		return nil, err
 	}
+	if i.InCludeModel {
+		data.upgradeModel()
+	}
+
+	return &data, nil
+}
+
+func (r roPresetRepo) SearchPublishedPresetsByItem(i SearchPresetsByItemInput) (*PartialSearchRoPresetResult, error) {
+	equipped := bson.M{"item_id": i.ItemId}
+	if i.Slot != nil {
+		equipped["slot"] = *i.Slot
+	}
+
+	// only the published copy is searched, drafts stay private
+	filter := bson.M{
+		"is_published":             true,
+		"published.equipped_items": bson.M{"$elemMatch": equipped},
+		"deleted_at":               nil,
+	}
+	if i.ClassId != nil {
+		filter["published.class_id"] = *i.ClassId
+	}
+	if i.Skill != nil {
+		filter["published.model.selectedAtkSkill"] = *i.Skill
+	}
+
+	return r.searchPublishedPresets(filter, i.Sort, i.Skip, i.Take)
+}
+
+func (r roPresetRepo) SearchPublishedPresetsByItemOption(i SearchPresetsByItemOptionInput) (*PartialSearchRoPresetResult, error) {
+	option := bson.M{"option": i.Option}
+	if i.Slot != nil {
+		option["slot"] = *i.Slot
+	}
+	if i.MinValue != nil {
+		option["value"] = bson.M{"$gte": *i.MinValue}
+	}
+
+	filter := bson.M{
+		"is_published":           true,
+		"published.item_options": bson.M{"$elemMatch": option},
+		"deleted_at":             nil,
+	}
+	if i.ClassId != nil {
+		filter["published.class_id"] = *i.ClassId
+	}
+	if i.Skill != nil {
+		filter["published.model.selectedAtkSkill"] = *i.Skill
+	}
+
+	return r.searchPublishedPresets(filter, i.Sort, i.Skip, i.Take)
+}
+
+func (r roPresetRepo) searchPublishedPresets(filter bson.M, sortBy PresetItemSearchSort, skip, take int) (*PartialSearchRoPresetResult, error) {
+	total, err := r.collection.CountDocuments(context.Background(), filter)
+	if err != nil {
+		return nil, err
+	}
+
+	sort := bson.D{{Key: "published_at", Value: -1}}
+	if sortBy != PresetItemSearchSorts.Latest {
+		sort = append(bson.D{{Key: "total_like", Value: -1}}, sort...)
+	}
+
+	cursor, err := r.collection.Find(context.Background(), filter, options.Find().
+		SetSort(sort).
+		SetSkip(int64(skip)).
+		SetLimit(int64(take)).
+		SetProjection(bson.M{"model": 0, "published.model": 0}))
+	if err != nil {
+		return nil, err
+	}
+
+	items := []RoPreset{}
+	err = cursor.All(context.Background(), &items)
+	if err != nil {
+		return nil, err
+	}
+
+	return &PartialSearchRoPresetResult{
+		Items: items,
+		Total: total,
+	}, nil
+}
+
+// forEachPresetBatch decodes every preset matching filter and bulk writes the update fn returns for it,
+// 500 writes at a time. A nil update skips the preset.
+func (r roPresetRepo) forEachPresetBatch(filter, projection bson.M, fn func(p *RoPreset) bson.M) (int, error) {
+	cursor, err := r.collection.Find(context.Background(), filter, options.Find().SetProjection(projection))
+	if err != nil {
+		return 0, err
+	}
+	defer cursor.Close(context.Background())
+
+	total := 0
+	writes := []mongo.WriteModel{}
+	flush := func() error {
+		if len(writes) == 0 {
+			return nil
+		}
+
+		_, err := r.collection.BulkWrite(context.Background(), writes, options.BulkWrite().SetOrdered(false))
+		writes = []mongo.WriteModel{}
+
+		return err
+	}
+
+	for cursor.Next(context.Background()) {
+		var p RoPreset
+		err = cursor.Decode(&p)
+		if err != nil {
+			return total, err
+		}
+
+		update := fn(&p)
+		if update == nil {
+			continue
+		}
+
+		writes = append(writes, mongo.NewUpdateOneModel().
+			SetFilter(bson.M{"id": p.Id}).
+			SetUpdate(update))
+		total++
+
+		if len(writes) >= 500 {
+			err = flush()
+			if err != nil {
+				return total, err
+			}
+		}
+	}
+	if err = cursor.Err(); err != nil {
+		return total, err
+	}
+
+	return total, flush()
+}
+
+// RebuildDerivedFields recomputes equipped_items and item_options of every preset and gives presets
+// published before drafts existed their published copy. Presets in the trash are
+// rebuilt too, so they are current when restored.
+func (r roPresetRepo) RebuildDerivedFields() (int, error) {
+	return r.forEachPresetBatch(bson.M{}, bson.M{
+		"id":           1,
+		"model":        1,
+		"is_published": 1,
+		"published":    1,
+		"published_at": 1,
+	}, func(p *RoPreset) bson.M {
+		itemOptions, unknownItemOptions := p.Model.ItemOptions()
+		set := bson.M{
+			"equipped_items":       p.Model.EquippedItems(),
+			"item_options":         itemOptions,
+			"unknown_item_options": unknownItemOptions,
+		}
+		if p.IsPublished && p.Published == nil {
+			set["published"] = p.legacyPublishedSnapshot()
+		} else if p.Published != nil {
+			publishedItemOptions, _ := p.Published.Model.ItemOptions()
+			set["published.equipped_items"] = p.Published.Model.EquippedItems()
+			set["published.item_options"] = publishedItemOptions
+		}
+
+		return bson.M{"$set": set}
+	})
+}
+
+// BackfillPublishedSnapshot gives a preset published before drafts existed its published copy,
+// it does nothing when the preset already has one.
+func (r roPresetRepo) BackfillPublishedSnapshot(id string) error {
+	filter := bson.M{"id": id, "is_published": true, "published": nil}
+
+	var p RoPreset
+	err := r.collection.FindOne(context.Background(), filter, options.FindOne().SetProjection(bson.M{
+		"id":           1,
+		"model":        1,
+		"published_at": 1,
+	})).Decode(&p)
+	if err == mongo.ErrNoDocuments {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	_, err = r.collection.UpdateOne(context.Background(), filter, bson.M{
+		"$set": bson.M{"published": p.legacyPublishedSnapshot()},
+	})
+
+	return err
+}
+
+// BackfillPublishedSnapshots is BackfillPublishedSnapshot for every preset, trash included.
+func (r roPresetRepo) BackfillPublishedSnapshots() (int, error) {
+	return r.forEachPresetBatch(bson.M{"is_published": true, "published": nil}, bson.M{
+		"id":           1,
+		"model":        1,
+		"published_at": 1,
+	}, func(p *RoPreset) bson.M {
+		return bson.M{"$set": bson.M{"published": p.legacyPublishedSnapshot()}}
+	})
+}
+
+// SampleItemOptionTexts gives the most used rawOptionTxts texts of every preset, trash included.
+func (r roPresetRepo) SampleItemOptionTexts(take int) ([]ItemOptionTextSample, error) {
+	cursor, err := r.collection.Aggregate(context.Background(), mongo.Pipeline{
+		{{Key: "$project", Value: bson.M{"raw": "$model.rawOptionTxts"}}},
+		{{Key: "$unwind", Value: bson.M{"path": "$raw", "includeArrayIndex": "index"}}},
+		{{Key: "$match", Value: bson.M{"raw": bson.M{"$type": "string", "$ne": ""}}}},
+		{{Key: "$group", Value: bson.M{
+			"_id":       "$raw",
+			"total_use": bson.M{"$sum": 1},
+			"indexes":   bson.M{"$addToSet": "$index"},
+		}}},
+		{{Key: "$sort", Value: bson.D{{Key: "total_use", Value: -1}, {Key: "_id", Value: 1}}}},
+		{{Key: "$limit", Value: take}},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	samples := []ItemOptionTextSample{}
+	err = cursor.All(context.Background(), &samples)
+	if err != nil {
+		return nil, err
+	}
+
+	return samples, nil
+}
+
+// MigrateSchemaVersion writes the upgraded model of every preset older than PresetSchemaVersion, trash included.
+func (r roPresetRepo) MigrateSchemaVersion() (int, error) {
+	return r.forEachPresetBatch(bson.M{
+		"schema_version": bson.M{"$not": bson.M{"$gte": PresetSchemaVersion}},
+	}, bson.M{
+		"id":             1,
+		"model":          1,
+		"schema_version": 1,
+		"published":      1,
+	}, func(p *RoPreset) bson.M {
+		p.upgradeModel()
+		itemOptions, unknownItemOptions := p.Model.ItemOptions()
+		set := bson.M{
+			"model":                p.Model,
+			"schema_version":       p.SchemaVersion,
+			"equipped_items":       p.Model.EquippedItems(),
+			"item_options":         itemOptions,
+			"unknown_item_options": unknownItemOptions,
+		}
+		if p.Published != nil {
+			set["published.model"] = p.Published.Model
+			set["published.schema_version"] = p.Published.SchemaVersion
+		}
+
+		return bson.M{"$set": set}
+	})
+}
+
+// MovePresetsToFolder only moves presets of userId, an empty folderId takes them out of their folder.
+func (r roPresetRepo) MovePresetsToFolder(userId string, presetIds []string, folderId string) (int, error) {
+	update := bson.M{"$set": bson.M{"folder_id": folderId}}
+	if folderId == "" {
+		update = bson.M{"$unset": bson.M{"folder_id": ""}}
+	}
+
+	res, err := r.collection.UpdateMany(context.Background(), bson.M{
+		"user_id":    userId,
+		"id":         bson.M{"$in": presetIds},
+		"deleted_at": nil,
+	}, update)
+	if err != nil {
+		return 0, err
+	}
+
+	return int(res.MatchedCount), nil
+}
+
+// RemovePresetsFromFolder also empties the folder of presets in the trash,
+// a restored preset never points to a folder that is gone.
+func (r roPresetRepo) RemovePresetsFromFolder(folderId string) error {
+	_, err := r.collection.UpdateMany(context.Background(), bson.M{"folder_id": folderId}, bson.M{
+		"$unset": bson.M{"folder_id": ""},
+	})
+
+	return err
+}
+
+// CountFolderPresetsOfOtherClass only counts presets outside of the trash.
+func (r roPresetRepo) CountFolderPresetsOfOtherClass(folderId string, classId int) (int, error) {
+	total, err := r.collection.CountDocuments(context.Background(), bson.M{
+		"folder_id":  folderId,
+		"class_id":   bson.M{"$ne": classId},
+		"deleted_at": nil,
+	})
+
+	return int(total), err
+}
+
+// RemoveOtherClassPresetsFromFolder takes presets of another class out of the folder,
+// including the ones in the trash.
+func (r roPresetRepo) RemoveOtherClassPresetsFromFolder(folderId string, classId int) error {
+	_, err := r.collection.UpdateMany(context.Background(), bson.M{
+		"folder_id": folderId,
+		"class_id":  bson.M{"$ne": classId},
+	}, bson.M{
+		"$unset": bson.M{"folder_id": ""},
+	})
+
+	return err
+}
+
+// CountPresetsByFolder counts presets outside of any folder under "".
+func (r roPresetRepo) CountPresetsByFolder(userId string) (map[string]int, error) {
+	cursor, err := r.collection.Aggregate(context.Background(), mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"user_id": userId, "deleted_at": nil}}},
+		{{Key: "$group", Value: bson.M{
+			"_id":   bson.M{"$ifNull": bson.A{"$folder_id", ""}},
+			"total": bson.M{"$sum": 1},
+		}}},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var rows []struct {
+		FolderId string `bson:"_id"`
+		Total    int    `bson:"total"`
+	}
+	err = cursor.All(context.Background(), &rows)
+	if err != nil {
+		return nil, err
+	}
+
+	counts := map[string]int{}
+	for _, v := range rows {
+		counts[v.FolderId] = v.Total
+	}
+
+	return counts, nil
+}
+
+func (r roPresetRepo) SoftDeletePresetById(id string, version *int) (int, error) {
+	res, err := r.collection.UpdateOne(context.Background(), r.versionFilter(id, version), bson.M{
+		"$set": bson.M{
+			"deleted_at": time.Now(),
+		},
+		"$inc": bson.M{
+			"version": 1,
+		},
+	})
+	if err != nil {
+		return 0, err
+	}
+	if res.MatchedCount == 0 && version != nil {
+		return 0, r.versionConflict(id)
+	}
+
+	return int(res.ModifiedCount), nil
+}
+
+func (r roPresetRepo) RestorePresetById(id string) (int, error) {
+	res, err := r.collection.UpdateOne(context.Background(), bson.M{
+		"id":         id,
+		"deleted_at": bson.M{"$ne": nil},
+	}, bson.M{
+		"$unset": bson.M{
+			"deleted_at": "",
+		},
+		"$inc": bson.M{
+			"version": 1,
+		},
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return int(res.ModifiedCount), nil
+}
+
+func (r roPresetRepo) FindDeletedPresetById(id string) (*RoPreset, error) {
+	var data RoPreset
+	err := r.collection.FindOne(context.Background(), bson.M{
+		"id":         id,
+		"deleted_at": bson.M{"$ne": nil},
+	}, options.FindOne().SetProjection(bson.M{
+		"model":           0,
+		"published.model": 0,
+	})).Decode(&data)
+	if err != nil {
+		return nil, err
+	}
 
 	return &data, nil
 }
+
+func (r roPresetRepo) FindDeletedPresetsByUserId(userId string) ([]RoPreset, error) {
+	cursor, err := r.collection.Find(context.Background(), bson.M{
+		"user_id":    userId,
+		"deleted_at": bson.M{"$ne": nil},
+	}, options.Find().SetProjection(bson.M{
+		"model":           0,
+		"published.model": 0,
+	}).SetSort(bson.M{
+		"deleted_at": -1,
+	}))
+	if err != nil {
+		return nil, err
+	}
+
+	presets := []RoPreset{}
+	err = cursor.All(context.Background(), &presets)
+	if err != nil {
+		return nil, err
+	}
+
+	return presets, nil
+}
+
+// FindPublishedPresetIdsByUserId includes presets in the trash, they would be public again after a restore.
+func (r roPresetRepo) FindPublishedPresetIdsByUserId(userId string) ([]string, error) {
+	ids, err := r.collection.Distinct(context.Background(), "id", bson.M{
+		"user_id":      userId,
+		"is_published": true,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	res := []string{}
+	for _, v := range ids {
+		if id, ok := v.(string); ok {
+			res = append(res, id)
+		}
+	}
+
+	return res, nil
+}
+
+func (r roPresetRepo) FindPresetIdsDeletedBefore(t time.Time) ([]string, error) {
+	cursor, err := r.collection.Find(context.Background(), bson.M{
+		"deleted_at": bson.M{"$lt": t},
+	}, options.Find().SetProjection(bson.M{
+		"id": 1,
+	}))
+	if err != nil {
+		return nil, err
+	}
+
+	var presets []RoPreset
+	err = cursor.All(context.Background(), &presets)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := []string{}
+	for _, v := range presets {
+		ids = append(ids, v.Id)
+	}
+
+	return ids, nil
+}
+
+// CountPublishedByUser counts by the class of the published copy, Since is on published_at.
+func (r roPresetRepo) CountPublishedByUser(i PublisherStatsInput) ([]PublisherCount, error) {
+	match := bson.M{"is_published": true, "deleted_at": nil}
+	if i.Since != nil {
+		match["published_at"] = bson.M{"$gte": *i.Since}
+	}
+	if i.UserId != "" {
+		match["user_id"] = i.UserId
+	}
+
+	pipeline := mongo.Pipeline{{{Key: "$match", Value: match}}}
+
+	return aggregatePublisherCounts(r.collection, append(pipeline, groupByPublisher("$user_id", bson.M{"$ifNull": bson.A{"$published.class_id", "$class_id"}})...))
+}
+
+// CountForksBySourceUser credits forks to the owner of the forked preset, forks of your own presets do not count.
+// Forks in the trash still count, fork_count is not lowered either.
+func (r roPresetRepo) CountForksBySourceUser(i PublisherStatsInput) ([]PublisherCount, error) {
+	match := bson.M{
+		"forked_from_user_id": bson.M{"$nin": bson.A{nil, ""}},
+		"$expr":               bson.M{"$ne": bson.A{"$forked_from_user_id", "$user_id"}},
+	}
+	if i.Since != nil {
+		match["created_at"] = bson.M{"$gte": *i.Since}
+	}
+	if i.UserId != "" {
+		match["forked_from_user_id"] = i.UserId
+	}
+
+	pipeline := mongo.Pipeline{{{Key: "$match", Value: match}}}
+
+	return aggregatePublisherCounts(r.collection, append(pipeline, groupByPublisher("$forked_from_user_id", "$class_id")...))
+}
@@ -0,0 +1,36 @@
+package service
+
+import "ro-backend/repository"
+
+type ImportItemInput struct {
+	Id              int      `json:"id" yaml:"id"`
+	Name            string   `json:"name" yaml:"name"`
+	NameTh          string   `json:"nameTh" yaml:"nameTh"`
+	Type            string   `json:"type" yaml:"type"`
+	Positions       []string `json:"positions" yaml:"positions"`
+	Refinable       bool     `json:"refinable" yaml:"refinable"`
+	CardSlots       int      `json:"cardSlots" yaml:"cardSlots"`
+	AllowedEnchants []int    `json:"allowedEnchants" yaml:"allowedEnchants"`
+}
+
+type ItemFileFormats struct {
+	Json string
+	Yaml string
+}
+
+var ItemFileFormat = ItemFileFormats{
+	Json: "json",
+	Yaml: "yaml",
+}
+
+type ImportItemsResult struct {
+	Total    int
+	Upserted int
+}
+
+type ItemService interface {
+	FindItemById(id int) (*repository.Item, error)
+	FindItemMap(ids []int) (map[int]repository.Item, error)
+	SearchItems(repository.SearchItemsInput) (*repository.SearchItemsResult, error)
+	ImportItems(data []byte, format string) (*ImportItemsResult, error)
+}
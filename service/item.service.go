@@ -0,0 +1,125 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"ro-backend/appError"
+	"ro-backend/repository"
+	"slices"
+
+	"gopkg.in/yaml.v3"
+)
+
+func NewItemService(itemRepo repository.ItemRepository) ItemService {
+	return itemService{itemRepo: itemRepo}
+}
+
+type itemService struct {
+	itemRepo repository.ItemRepository
+}
+
+var itemTypes = []string{
+	repository.ItemType.Weapon,
+	repository.ItemType.Armor,
+	repository.ItemType.Card,
+	repository.ItemType.Enchant,
+	repository.ItemType.Ammo,
+	repository.ItemType.Pet,
+	repository.ItemType.Costume,
+	repository.ItemType.Shadow,
+	repository.ItemType.Etc,
+}
+
+func (s itemService) FindItemById(id int) (*repository.Item, error) {
+	return s.itemRepo.FindItemById(id)
+}
+
+func (s itemService) FindItemMap(ids []int) (map[int]repository.Item, error) {
+	items, err := s.itemRepo.FindItemsByIds(ids)
+	if err != nil {
+		return nil, err
+	}
+
+	itemMap := map[int]repository.Item{}
+	for _, v := range items {
+		itemMap[v.Id] = v
+	}
+
+	return itemMap, nil
+}
+
+func (s itemService) SearchItems(i repository.SearchItemsInput) (*repository.SearchItemsResult, error) {
+	return s.itemRepo.SearchItems(i)
+}
+
+// ImportItems accepts a list of items or an object keyed by item id, as JSON or YAML.
+func (s itemService) ImportItems(data []byte, format string) (*ImportItemsResult, error) {
+	inputs, err := decodeItemFile(data, format)
+	if err != nil {
+		return nil, err
+	}
+
+	items := []repository.Item{}
+	for _, v := range inputs {
+		if v.Id <= 0 || v.Name == "" || !slices.Contains(itemTypes, v.Type) || v.CardSlots < 0 {
+			return nil, fmt.Errorf(appError.ErrInvalidItemInput)
+		}
+
+		positions := v.Positions
+		if positions == nil {
+			positions = []string{}
+		}
+		enchants := v.AllowedEnchants
+		if enchants == nil {
+			enchants = []int{}
+		}
+
+		items = append(items, repository.Item{
+			Id:              v.Id,
+			Name:            v.Name,
+			NameTh:          v.NameTh,
+			Type:            v.Type,
+			Positions:       positions,
+			Refinable:       v.Refinable,
+			CardSlots:       v.CardSlots,
+			AllowedEnchants: enchants,
+		})
+	}
+
+	upserted, err := s.itemRepo.UpsertItems(items)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ImportItemsResult{
+		Total:    len(items),
+		Upserted: upserted,
+	}, nil
+}
+
+func decodeItemFile(data []byte, format string) ([]ImportItemInput, error) {
+	unmarshal := json.Unmarshal
+	if format == ItemFileFormat.Yaml {
+		unmarshal = yaml.Unmarshal
+	}
+
+	var list []ImportItemInput
+	if err := unmarshal(data, &list); err == nil {
+		return list, nil
+	}
+
+	var keyed map[string]ImportItemInput
+	if err := unmarshal(data, &keyed); err != nil {
+		return nil, fmt.Errorf(appError.ErrInvalidItemInput)
+	}
+
+	list = []ImportItemInput{}
+	for _, v := range keyed {
+		list = append(list, v)
+	}
+	slices.SortFunc(list, func(a, b ImportItemInput) int {
+		return a.Id - b.Id
+	})
+
+	return list, nil
+}
@@ -0,0 +1,28 @@
+package service
+
+import "ro-backend/repository"
+
+type ReportRequest struct {
+	TargetType repository.ReportTargetType
+	TargetId   string
+	ReporterId string
+	Reason     string
+	Comment    string
+}
+
+type ModerationActionRequest struct {
+	AdminId    string
+	TargetType repository.ReportTargetType
+	TargetId   string
+	Action     repository.ModerationAction
+	Note       string
+}
+
+type ModerationService interface {
+	Report(ReportRequest) (*repository.PresetReport, error)
+	FindQueue(skip, take int) (*repository.ModerationQueueResult, error)
+	FindOpenReports(targetType repository.ReportTargetType, targetId string) ([]repository.PresetReport, error)
+	// Act closes the open reports of the target and writes the audit log of the action
+	Act(ModerationActionRequest) (*repository.ModerationAuditLog, error)
+	FindAuditLogs(repository.FindAuditLogsInput) (*repository.FindAuditLogsResult, error)
+}
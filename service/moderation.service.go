@@ -0,0 +1,235 @@
+package service
+
+import (
+	"fmt"
+	"ro-backend/appError"
+	"ro-backend/repository"
+	"strings"
+	"unicode/utf8"
+
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+const maxReportCommentLength = 500
+
+type ModerationServiceParam struct {
+	ReportRepo    repository.PresetReportRepository
+	AuditRepo     repository.ModerationAuditRepository
+	PresetRepo    repository.RoPresetRepository
+	TagRepo       repository.PresetTagRepository
+	UserRepo      repository.UserRepository
+	PresetService RoPresetService
+}
+
+func NewModerationService(p ModerationServiceParam) ModerationService {
+	return moderationService{
+		reportRepo:    p.ReportRepo,
+		auditRepo:     p.AuditRepo,
+		presetRepo:    p.PresetRepo,
+		tagRepo:       p.TagRepo,
+		userRepo:      p.UserRepo,
+		presetService: p.PresetService,
+	}
+}
+
+type moderationService struct {
+	reportRepo    repository.PresetReportRepository
+	auditRepo     repository.ModerationAuditRepository
+	presetRepo    repository.RoPresetRepository
+	tagRepo       repository.PresetTagRepository
+	userRepo      repository.UserRepository
+	presetService RoPresetService
+}
+
+type moderationTarget struct {
+	label       string
+	presetId    string
+	publisherId string
+}
+
+// findTarget with publishedOnly only finds presets that can still be reported.
+func (s moderationService) findTarget(targetType repository.ReportTargetType, targetId string, publishedOnly bool) (*moderationTarget, error) {
+	switch targetType {
+	case repository.ReportTargetTypes.Preset:
+		p, err := s.presetRepo.FindPresetById(repository.FindPresetByIdInput{
+			Id:           targetId,
+			InCludeModel: false,
+		})
+		if err != nil {
+			return nil, err
+		}
+		if publishedOnly && !p.IsPublished {
+			return nil, mongo.ErrNoDocuments
+		}
+
+		return &moderationTarget{label: p.PublishName, presetId: p.Id, publisherId: p.UserId}, nil
+	case repository.ReportTargetTypes.Tag:
+		t, err := s.tagRepo.FindTagById(targetId)
+		if err != nil {
+			return nil, err
+		}
+
+		return &moderationTarget{label: t.Tag, presetId: t.PresetId, publisherId: t.PublisherId}, nil
+	}
+
+	return nil, fmt.Errorf(appError.ErrBadInput)
+}
+
+func (s moderationService) Report(r ReportRequest) (*repository.PresetReport, error) {
+	reason := repository.ReportReason(r.Reason)
+	comment := strings.TrimSpace(r.Comment)
+	if !reason.IsValid() || utf8.RuneCountInString(comment) > maxReportCommentLength {
+		return nil, fmt.Errorf(appError.ErrInvalidReportInput)
+	}
+	if reason == repository.ReportReasons.Other && comment == "" {
+		return nil, fmt.Errorf(appError.ErrInvalidReportInput)
+	}
+
+	err := checkUserActive(s.userRepo, r.ReporterId)
+	if err != nil {
+		return nil, err
+	}
+
+	target, err := s.findTarget(r.TargetType, r.TargetId, true)
+	if err != nil {
+		return nil, err
+	}
+	if target.publisherId == r.ReporterId {
+		return nil, fmt.Errorf(appError.ErrInvalidReportInput)
+	}
+
+	report, err := s.reportRepo.CreateReport(repository.CreateReportInput{
+		TargetType:  r.TargetType,
+		TargetId:    r.TargetId,
+		TargetLabel: target.label,
+		PresetId:    target.presetId,
+		PublisherId: target.publisherId,
+		ReporterId:  r.ReporterId,
+		Reason:      reason,
+		Comment:     comment,
+	})
+	if mongo.IsDuplicateKeyError(err) {
+		return nil, fmt.Errorf(appError.ErrReportAlreadyOpen)
+	}
+
+	return report, err
+}
+
+func (s moderationService) FindQueue(skip, take int) (*repository.ModerationQueueResult, error) {
+	return s.reportRepo.FindModerationQueue(skip, take)
+}
+
+func (s moderationService) FindOpenReports(targetType repository.ReportTargetType, targetId string) ([]repository.PresetReport, error) {
+	if !targetType.IsValid() {
+		return nil, fmt.Errorf(appError.ErrBadInput)
+	}
+
+	return s.reportRepo.FindOpenReports(targetType, targetId)
+}
+
+// Act on a target that is already gone, like a tag removed with its preset, uses what the reports recorded.
+func (s moderationService) Act(r ModerationActionRequest) (*repository.ModerationAuditLog, error) {
+	if !r.Action.IsValid() || !r.TargetType.IsValid() {
+		return nil, fmt.Errorf(appError.ErrBadInput)
+	}
+
+	reports, err := s.reportRepo.FindOpenReports(r.TargetType, r.TargetId)
+	if err != nil {
+		return nil, err
+	}
+
+	target, err := s.findTarget(r.TargetType, r.TargetId, false)
+	if err == mongo.ErrNoDocuments && len(reports) > 0 {
+		target = &moderationTarget{
+			label:       reports[0].TargetLabel,
+			presetId:    reports[0].PresetId,
+			publisherId: reports[0].PublisherId,
+		}
+		err = nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	status := repository.ReportStatuses.Resolved
+	totalUnpublished := 0
+	switch r.Action {
+	case repository.ModerationActions.Dismiss:
+		status = repository.ReportStatuses.Dismissed
+	case repository.ModerationActions.Unpublish:
+		totalUnpublished, err = s.takeDown(r.TargetType, r.TargetId, target)
+	case repository.ModerationActions.Ban:
+		totalUnpublished, err = s.ban(target.publisherId)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	reportIds, err := s.reportRepo.ResolveReports(repository.ResolveReportsInput{
+		TargetType: r.TargetType,
+		TargetId:   r.TargetId,
+		Status:     status,
+		Action:     r.Action,
+		ResolvedBy: r.AdminId,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.auditRepo.CreateAuditLog(repository.ModerationAuditLog{
+		AdminId:          r.AdminId,
+		Action:           r.Action,
+		TargetType:       r.TargetType,
+		TargetId:         r.TargetId,
+		TargetLabel:      target.label,
+		PresetId:         target.presetId,
+		PublisherId:      target.publisherId,
+		Note:             strings.TrimSpace(r.Note),
+		ReportIds:        reportIds,
+		TotalUnpublished: totalUnpublished,
+	})
+}
+
+// takeDown unpublishes a reported preset, a reported tag is only removed from its preset.
+func (s moderationService) takeDown(targetType repository.ReportTargetType, targetId string, target *moderationTarget) (int, error) {
+	if targetType == repository.ReportTargetTypes.Tag {
+		return 0, s.tagRepo.DeleteTag(targetId)
+	}
+
+	p, err := s.presetRepo.FindPresetById(repository.FindPresetByIdInput{
+		Id:           target.presetId,
+		InCludeModel: false,
+	})
+	if err != nil {
+		return 0, err
+	}
+	if !p.IsPublished {
+		return 0, nil
+	}
+
+	return 1, s.presetService.ForceUnpublishPreset(p.Id)
+}
+
+// ban makes the publisher inactive, which stops sign in, token refresh, publishing, tagging and reporting,
+// and unpublishes all of their presets.
+func (s moderationService) ban(publisherId string) (int, error) {
+	user, err := s.userRepo.FindUserById(publisherId)
+	if err != nil {
+		return 0, err
+	}
+	if user.Role == repository.UserRole.Admin {
+		return 0, fmt.Errorf(appError.ErrForbidden)
+	}
+
+	err = s.userRepo.PatchUser(publisherId, repository.UpdateUserInput{
+		Status: repository.UserStatus.InActive,
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return s.presetService.ForceUnpublishUserPresets(publisherId)
+}
+
+func (s moderationService) FindAuditLogs(i repository.FindAuditLogsInput) (*repository.FindAuditLogsResult, error) {
+	return s.auditRepo.FindAuditLogs(i)
+}
@@ -0,0 +1,55 @@
+package service
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"ro-backend/appError"
+	"ro-backend/repository"
+	"time"
+)
+
+// presetCursor keeps the sort in the cursor, a cursor is only valid for the sort it was made with.
+type presetCursor struct {
+	Sort repository.PresetListSort `json:"s"`
+	At   time.Time                 `json:"t"`
+	Id   string                    `json:"i"`
+}
+
+func encodePresetCursor(sort repository.PresetListSort, p repository.RoPreset) string {
+	at := p.UpdatedAt
+	if sort == repository.PresetListSorts.CreatedDesc || sort == repository.PresetListSorts.CreatedAsc {
+		at = p.CreatedAt
+	}
+
+	data, _ := json.Marshal(presetCursor{
+		Sort: sort,
+		At:   at,
+		Id:   p.Id,
+	})
+
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// decodePresetCursor returns nil for an empty cursor, the first page.
+func decodePresetCursor(raw string, sort repository.PresetListSort) (*repository.PresetListCursor, error) {
+	if raw == "" {
+		return nil, nil
+	}
+
+	data, err := base64.RawURLEncoding.DecodeString(raw)
+	if err != nil {
+		return nil, fmt.Errorf(appError.ErrInvalidPresetCursor)
+	}
+
+	var c presetCursor
+	err = json.Unmarshal(data, &c)
+	if err != nil || c.Sort != sort || c.Id == "" {
+		return nil, fmt.Errorf(appError.ErrInvalidPresetCursor)
+	}
+
+	return &repository.PresetListCursor{
+		At: c.At,
+		Id: c.Id,
+	}, nil
+}
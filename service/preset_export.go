@@ -0,0 +1,43 @@
+package service
+
+import (
+	"ro-backend/repository"
+	"time"
+)
+
+const PresetExportSchemaVersion = 1
+const PresetExportKind = "ro-preset-export"
+
+type ExportedPreset struct {
+	Label   string                 `json:"label"`
+	ClassId int                    `json:"classId"`
+	Model   repository.PresetModel `json:"model"`
+}
+
+// PresetExport is the portable format, SchemaVersion tells which upgrade steps an import needs.
+type PresetExport struct {
+	SchemaVersion int              `json:"schemaVersion"`
+	Kind          string           `json:"kind"`
+	ExportedAt    time.Time        `json:"exportedAt"`
+	Presets       []ExportedPreset `json:"presets"`
+}
+
+type PresetExportResult struct {
+	Export    PresetExport
+	ShareCode string
+}
+
+type ImportPresetsRequest struct {
+	UserId   string
+	UserName string
+	Role     string
+	// either the export json or its share code
+	Data      []byte
+	ShareCode string
+}
+
+type PresetExportService interface {
+	ExportPreset(CheckPresetOwnerRequest) (*PresetExportResult, error)
+	ExportMyPresets(userId string) (*PresetExportResult, error)
+	ImportPresets(ImportPresetsRequest) ([]repository.RoPreset, error)
+}
@@ -0,0 +1,222 @@
+package service
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"ro-backend/appError"
+	"ro-backend/repository"
+	"strings"
+	"time"
+)
+
+// share codes bigger than this after decompression are rejected
+const maxPresetExportSize = 5 << 20
+
+// presetExportUpgrades[v] turns a version v export into version v+1.
+var presetExportUpgrades = map[int]func(json.RawMessage) (json.RawMessage, error){
+	0: upgradePresetExportV0,
+}
+
+func NewPresetExportService(presetService RoPresetService) PresetExportService {
+	return presetExportService{presetService: presetService}
+}
+
+type presetExportService struct {
+	presetService RoPresetService
+}
+
+func (s presetExportService) ExportPreset(r CheckPresetOwnerRequest) (*PresetExportResult, error) {
+	p, err := s.presetService.FindPresetById(r)
+	if err != nil {
+		return nil, err
+	}
+
+	return newPresetExportResult([]repository.RoPreset{*p})
+}
+
+func (s presetExportService) ExportMyPresets(userId string) (*PresetExportResult, error) {
+	presets, err := s.presetService.FindPresetsByUserId(userId, true)
+	if err != nil {
+		return nil, err
+	}
+
+	return newPresetExportResult(presets)
+}
+
+func (s presetExportService) ImportPresets(r ImportPresetsRequest) ([]repository.RoPreset, error) {
+	data := r.Data
+	if r.ShareCode != "" {
+		decoded, err := decodeShareCode(r.ShareCode)
+		if err != nil {
+			return nil, err
+		}
+		data = decoded
+	}
+
+	export, err := parsePresetExport(data)
+	if err != nil {
+		return nil, err
+	}
+	if len(export.Presets) == 0 {
+		return nil, fmt.Errorf(appError.ErrInvalidPresetExport)
+	}
+
+	input := repository.BulkCreatePresetInput{
+		UserId:   r.UserId,
+		UserName: r.UserName,
+		Role:     r.Role,
+	}
+	for _, v := range export.Presets {
+		// models from other calculators may be any older shape
+		repository.UpgradePresetModel(&v.Model, 0)
+		input.BulkData = append(input.BulkData, repository.BulkCreatePresetData{
+			Label: v.Label,
+			Model: v.Model,
+		})
+	}
+
+	// validation and quota are the same as a bulk create
+	return s.presetService.BulkCreatePresets(input)
+}
+
+func newPresetExportResult(presets []repository.RoPreset) (*PresetExportResult, error) {
+	export := PresetExport{
+		SchemaVersion: PresetExportSchemaVersion,
+		Kind:          PresetExportKind,
+		ExportedAt:    time.Now(),
+		Presets:       []ExportedPreset{},
+	}
+	for _, v := range presets {
+		export.Presets = append(export.Presets, ExportedPreset{
+			Label:   v.Label,
+			ClassId: v.Model.Class,
+			Model:   v.Model,
+		})
+	}
+
+	shareCode, err := encodeShareCode(export)
+	if err != nil {
+		return nil, err
+	}
+
+	return &PresetExportResult{
+		Export:    export,
+		ShareCode: shareCode,
+	}, nil
+}
+
+// parsePresetExport runs the upgrade steps until the data is the current schema version.
+func parsePresetExport(data []byte) (*PresetExport, error) {
+	raw := json.RawMessage(bytes.TrimSpace(data))
+	if len(raw) == 0 {
+		return nil, fmt.Errorf(appError.ErrInvalidPresetExport)
+	}
+
+	for {
+		version, err := presetExportVersion(raw)
+		if err != nil {
+			return nil, err
+		}
+		if version == PresetExportSchemaVersion {
+			break
+		}
+
+		upgrade, found := presetExportUpgrades[version]
+		if !found {
+			return nil, fmt.Errorf(appError.ErrUnsupportedPresetExport)
+		}
+
+		raw, err = upgrade(raw)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var export PresetExport
+	err := json.Unmarshal(raw, &export)
+	if err != nil || export.Kind != PresetExportKind {
+		return nil, fmt.Errorf(appError.ErrInvalidPresetExport)
+	}
+
+	return &export, nil
+}
+
+// presetExportVersion treats a bare array as version 0, the shape of /me/ro_entire_presets.
+func presetExportVersion(raw json.RawMessage) (int, error) {
+	if raw[0] == '[' {
+		return 0, nil
+	}
+
+	var header struct {
+		SchemaVersion int `json:"schemaVersion"`
+	}
+	err := json.Unmarshal(raw, &header)
+	if err != nil {
+		return 0, fmt.Errorf(appError.ErrInvalidPresetExport)
+	}
+
+	return header.SchemaVersion, nil
+}
+
+func upgradePresetExportV0(raw json.RawMessage) (json.RawMessage, error) {
+	var presets []ExportedPreset
+	err := json.Unmarshal(raw, &presets)
+	if err != nil {
+		return nil, fmt.Errorf(appError.ErrInvalidPresetExport)
+	}
+
+	for i := range presets {
+		presets[i].ClassId = presets[i].Model.Class
+	}
+
+	return json.Marshal(PresetExport{
+		SchemaVersion: 1,
+		Kind:          PresetExportKind,
+		Presets:       presets,
+	})
+}
+
+// encodeShareCode gzips the export json and encodes it with url safe base64.
+func encodeShareCode(export PresetExport) (string, error) {
+	data, err := json.Marshal(export)
+	if err != nil {
+		return "", err
+	}
+
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	_, err = zw.Write(data)
+	if err != nil {
+		return "", err
+	}
+	err = zw.Close()
+	if err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(buf.Bytes()), nil
+}
+
+func decodeShareCode(code string) ([]byte, error) {
+	compressed, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(strings.TrimSpace(code), "="))
+	if err != nil {
+		return nil, fmt.Errorf(appError.ErrInvalidPresetExport)
+	}
+
+	zr, err := gzip.NewReader(bytes.NewReader(compressed))
+	if err != nil {
+		return nil, fmt.Errorf(appError.ErrInvalidPresetExport)
+	}
+	defer zr.Close()
+
+	data, err := io.ReadAll(io.LimitReader(zr, maxPresetExportSize+1))
+	if err != nil || len(data) > maxPresetExportSize {
+		return nil, fmt.Errorf(appError.ErrInvalidPresetExport)
+	}
+
+	return data, nil
+}
@@ -0,0 +1,53 @@
+package service
+
+import "ro-backend/repository"
+
+type CreatePresetFolderRequest struct {
+	UserId  string
+	Name    string
+	ClassId int
+}
+
+type UpdatePresetFolderRequest struct {
+	Id      string
+	UserId  string
+	Name    string
+	ClassId *int
+}
+
+type ReorderPresetFoldersRequest struct {
+	UserId    string
+	FolderIds []string
+}
+
+type PresetFolderRequest struct {
+	Id     string
+	UserId string
+}
+
+// MovePresetsRequest with an empty FolderId takes the presets out of their folder.
+type MovePresetsRequest struct {
+	UserId    string
+	FolderId  string
+	PresetIds []string
+}
+
+type PresetFolderWithCount struct {
+	repository.PresetFolder
+	TotalPreset int
+}
+
+type MyPresetFolders struct {
+	Folders []PresetFolderWithCount
+	// presets outside of any folder
+	TotalUnfiled int
+}
+
+type PresetFolderService interface {
+	FindMyFolders(userId string) (*MyPresetFolders, error)
+	CreateFolder(CreatePresetFolderRequest) (*repository.PresetFolder, error)
+	UpdateFolder(UpdatePresetFolderRequest) (*repository.PresetFolder, error)
+	ReorderFolders(ReorderPresetFoldersRequest) ([]repository.PresetFolder, error)
+	DeleteFolder(PresetFolderRequest) error
+	MovePresets(MovePresetsRequest) (int, error)
+}
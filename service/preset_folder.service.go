@@ -0,0 +1,234 @@
+package service
+
+import (
+	"fmt"
+	"ro-backend/appError"
+	"ro-backend/repository"
+	"strings"
+	"unicode/utf8"
+
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+const maxFolderPerUser = 100
+const maxFolderNameLength = 50
+
+func NewPresetFolderService(folderRepo repository.PresetFolderRepository, presetRepo repository.RoPresetRepository) PresetFolderService {
+	return presetFolderService{
+		folderRepo: folderRepo,
+		presetRepo: presetRepo,
+	}
+}
+
+type presetFolderService struct {
+	folderRepo repository.PresetFolderRepository
+	presetRepo repository.RoPresetRepository
+}
+
+func (s presetFolderService) validateFolderOwner(r PresetFolderRequest) (*repository.PresetFolder, error) {
+	folder, err := s.folderRepo.FindFolderById(r.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	if folder.UserId != r.UserId {
+		return nil, fmt.Errorf(appError.ErrNotMyFolder)
+	}
+
+	return folder, nil
+}
+
+func validFolderName(name string) (string, bool) {
+	name = strings.TrimSpace(name)
+
+	return name, name != "" && utf8.RuneCountInString(name) <= maxFolderNameLength
+}
+
+// checkFolderClass keeps a preset in a folder of one class from changing to another class.
+func checkFolderClass(folderRepo repository.PresetFolderRepository, p repository.RoPreset, classId int) error {
+	if p.FolderId == "" || p.ClassId == classId {
+		return nil
+	}
+
+	folder, err := folderRepo.FindFolderById(p.FolderId)
+	if err == mongo.ErrNoDocuments {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	if folder.ClassId != 0 && folder.ClassId != classId {
+		return fmt.Errorf(appError.ErrFolderClassMismatch)
+	}
+
+	return nil
+}
+
+func (s presetFolderService) FindMyFolders(userId string) (*MyPresetFolders, error) {
+	folders, err := s.folderRepo.FindFoldersByUserId(userId)
+	if err != nil {
+		return nil, err
+	}
+
+	counts, err := s.presetRepo.CountPresetsByFolder(userId)
+	if err != nil {
+		return nil, err
+	}
+
+	res := MyPresetFolders{
+		Folders:      []PresetFolderWithCount{},
+		TotalUnfiled: counts[""],
+	}
+	for _, v := range folders {
+		res.Folders = append(res.Folders, PresetFolderWithCount{
+			PresetFolder: v,
+			TotalPreset:  counts[v.Id],
+		})
+	}
+
+	return &res, nil
+}
+
+func (s presetFolderService) CreateFolder(r CreatePresetFolderRequest) (*repository.PresetFolder, error) {
+	name, ok := validFolderName(r.Name)
+	if !ok || r.ClassId < 0 {
+		return nil, fmt.Errorf(appError.ErrInvalidFolderInput)
+	}
+
+	total, err := s.folderRepo.CountFoldersByUserId(r.UserId)
+	if err != nil {
+		return nil, err
+	}
+	if total >= maxFolderPerUser {
+		return nil, fmt.Errorf(appError.ErrFolderLimitExceeded)
+	}
+
+	// new folders go last, the count is not enough once a folder was deleted
+	maxOrder, err := s.folderRepo.FindMaxOrder(r.UserId)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.folderRepo.CreateFolder(repository.CreatePresetFolderInput{
+		UserId:  r.UserId,
+		Name:    name,
+		ClassId: r.ClassId,
+		Order:   maxOrder + 1,
+	})
+}
+
+func (s presetFolderService) UpdateFolder(r UpdatePresetFolderRequest) (*repository.PresetFolder, error) {
+	_, err := s.validateFolderOwner(PresetFolderRequest{Id: r.Id, UserId: r.UserId})
+	if err != nil {
+		return nil, err
+	}
+
+	i := repository.UpdatePresetFolderInput{ClassId: r.ClassId}
+	if r.Name != "" {
+		name, ok := validFolderName(r.Name)
+		if !ok {
+			return nil, fmt.Errorf(appError.ErrInvalidFolderInput)
+		}
+		i.Name = name
+	}
+	if r.ClassId != nil && *r.ClassId < 0 {
+		return nil, fmt.Errorf(appError.ErrInvalidFolderInput)
+	}
+
+	// the folder only takes a class its presets already have,
+	// presets of another class in the trash are moved out instead
+	if r.ClassId != nil && *r.ClassId != 0 {
+		total, err := s.presetRepo.CountFolderPresetsOfOtherClass(r.Id, *r.ClassId)
+		if err != nil {
+			return nil, err
+		}
+		if total > 0 {
+			return nil, fmt.Errorf(appError.ErrFolderClassMismatch)
+		}
+
+		err = s.presetRepo.RemoveOtherClassPresetsFromFolder(r.Id, *r.ClassId)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	err = s.folderRepo.UpdateFolder(r.Id, i)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.folderRepo.FindFolderById(r.Id)
+}
+
+// ReorderFolders needs every folder of the user exactly once.
+func (s presetFolderService) ReorderFolders(r ReorderPresetFoldersRequest) ([]repository.PresetFolder, error) {
+	folders, err := s.folderRepo.FindFoldersByUserId(r.UserId)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(r.FolderIds) != len(folders) {
+		return nil, fmt.Errorf(appError.ErrInvalidFolderInput)
+	}
+
+	mine := map[string]bool{}
+	for _, v := range folders {
+		mine[v.Id] = true
+	}
+	for _, id := range r.FolderIds {
+		if !mine[id] {
+			return nil, fmt.Errorf(appError.ErrInvalidFolderInput)
+		}
+		delete(mine, id)
+	}
+
+	err = s.folderRepo.ReorderFolders(r.UserId, r.FolderIds)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.folderRepo.FindFoldersByUserId(r.UserId)
+}
+
+// DeleteFolder keeps the presets, they are moved out of the folder.
+func (s presetFolderService) DeleteFolder(r PresetFolderRequest) error {
+	_, err := s.validateFolderOwner(r)
+	if err != nil {
+		return err
+	}
+
+	err = s.presetRepo.RemovePresetsFromFolder(r.Id)
+	if err != nil {
+		return err
+	}
+
+	return s.folderRepo.DeleteFolder(r.Id)
+}
+
+func (s presetFolderService) MovePresets(r MovePresetsRequest) (int, error) {
+	if len(r.PresetIds) == 0 {
+		return 0, fmt.Errorf(appError.ErrInvalidFolderInput)
+	}
+
+	if r.FolderId != "" {
+		folder, err := s.validateFolderOwner(PresetFolderRequest{Id: r.FolderId, UserId: r.UserId})
+		if err != nil {
+			return 0, err
+		}
+
+		if folder.ClassId != 0 {
+			presets, err := s.presetRepo.FindPresetByIds(r.PresetIds)
+			if err != nil {
+				return 0, err
+			}
+			for _, v := range presets {
+				if v.UserId == r.UserId && v.ClassId != folder.ClassId {
+					return 0, fmt.Errorf(appError.ErrFolderClassMismatch)
+				}
+			}
+		}
+	}
+
+	return s.presetRepo.MovePresetsToFolder(r.UserId, r.PresetIds, r.FolderId)
+}
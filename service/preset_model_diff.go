@@ -0,0 +1,151 @@
+package service
+
+import (
+	"fmt"
+	"reflect"
+	"ro-backend/repository"
+	"sort"
+	"strings"
+)
+
+type PresetModelFieldDiff struct {
+	Field string      `json:"field"`
+	From  interface{} `json:"from"`
+	To    interface{} `json:"to"`
+}
+
+type PresetSlotDiff struct {
+	Slot    string                 `json:"slot"`
+	Changes []PresetModelFieldDiff `json:"changes"`
+}
+
+// non equipment fields are grouped by what they are, the rest falls into "others"
+var presetDiffGroups = []struct {
+	Slot   string
+	Fields []string
+}{
+	{"stats", []string{"class", "level", "jobLevel", "str", "jobStr", "agi", "jobAgi", "vit", "jobVit", "int", "jobInt", "dex", "jobDex", "luk", "jobLuk"}},
+	{"traits", []string{"pow", "jobPow", "sta", "jobSta", "wis", "jobWis", "spl", "jobSpl", "con", "jobCon", "crt", "jobCrt"}},
+	{"buffs", []string{"skillBuffMap", "skillBuffs"}},
+	{"activeSkills", []string{"selectedAtkSkill", "propertyAtk", "activeSkillMap", "activeSkills"}},
+	{"passiveSkills", []string{"passiveSkillMap", "passiveSkills"}},
+	{"consumables", []string{"consumables", "consumables2", "aspdPotion", "aspdPotions"}},
+}
+
+const otherPresetDiffSlot = "others"
+
+// DiffPresetModel compares every field of PresetModel by its bson name,
+// skill maps are compared key by key as "<field>.<key>".
+func DiffPresetModel(a, b repository.PresetModel) []PresetModelFieldDiff {
+	diffs := []PresetModelFieldDiff{}
+
+	va := reflect.ValueOf(a)
+	vb := reflect.ValueOf(b)
+	t := va.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := strings.Split(t.Field(i).Tag.Get("bson"), ",")[0]
+		fa := va.Field(i)
+		fb := vb.Field(i)
+
+		if fa.Kind() == reflect.Map {
+			diffs = append(diffs, diffIntMap(field, fa.Interface().(map[string]int), fb.Interface().(map[string]int))...)
+			continue
+		}
+
+		if !reflect.DeepEqual(normalizeEmpty(fa), normalizeEmpty(fb)) {
+			diffs = append(diffs, PresetModelFieldDiff{
+				Field: field,
+				From:  fa.Interface(),
+				To:    fb.Interface(),
+			})
+		}
+	}
+
+	return diffs
+}
+
+// GroupPresetModelDiff puts each change under its equipment slot, slots keep the order of PresetModel.Equipments.
+func GroupPresetModelDiff(diffs []PresetModelFieldDiff) []PresetSlotDiff {
+	slotOrder := []string{}
+	slotByField := map[string]string{}
+	for _, e := range (repository.PresetModel{}).Equipments() {
+		slotOrder = append(slotOrder, e.Position)
+		slotByField[e.Position] = e.Position
+		slotByField[e.Position+"Refine"] = e.Position
+		slotByField[e.Position+"Grade"] = e.Position
+		for _, v := range e.Cards {
+			slotByField[v.Field] = e.Position
+		}
+		for _, v := range e.Enchants {
+			slotByField[v.Field] = e.Position
+		}
+	}
+	for _, g := range presetDiffGroups {
+		slotOrder = append(slotOrder, g.Slot)
+		for _, f := range g.Fields {
+			slotByField[f] = g.Slot
+		}
+	}
+	slotOrder = append(slotOrder, otherPresetDiffSlot)
+
+	changesBySlot := map[string][]PresetModelFieldDiff{}
+	for _, v := range diffs {
+		// map keys are diffed as "<field>.<key>"
+		field := strings.Split(v.Field, ".")[0]
+		slot, found := slotByField[field]
+		if !found {
+			slot = otherPresetDiffSlot
+		}
+		changesBySlot[slot] = append(changesBySlot[slot], v)
+	}
+
+	slots := []PresetSlotDiff{}
+	for _, slot := range slotOrder {
+		if len(changesBySlot[slot]) > 0 {
+			slots = append(slots, PresetSlotDiff{
+				Slot:    slot,
+				Changes: changesBySlot[slot],
+			})
+		}
+	}
+
+	return slots
+}
+
+func diffIntMap(field string, a, b map[string]int) []PresetModelFieldDiff {
+	keys := map[string]bool{}
+	for k := range a {
+		keys[k] = true
+	}
+	for k := range b {
+		keys[k] = true
+	}
+
+	sortedKeys := []string{}
+	for k := range keys {
+		sortedKeys = append(sortedKeys, k)
+	}
+	sort.Strings(sortedKeys)
+
+	diffs := []PresetModelFieldDiff{}
+	for _, k := range sortedKeys {
+		if a[k] != b[k] {
+			diffs = append(diffs, PresetModelFieldDiff{
+				Field: fmt.Sprintf("%v.%v", field, k),
+				From:  a[k],
+				To:    b[k],
+			})
+		}
+	}
+
+	return diffs
+}
+
+// normalizeEmpty treats nil and empty slices as the same value.
+func normalizeEmpty(v reflect.Value) interface{} {
+	if v.Kind() == reflect.Slice && v.Len() == 0 {
+		return nil
+	}
+
+	return v.Interface()
+}
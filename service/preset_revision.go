@@ -0,0 +1,38 @@
+package service
+
+import "ro-backend/repository"
+
+type PresetRevisionRequest struct {
+	PresetId string
+	UserId   string
+	Revision int
+}
+
+// RestorePresetRevisionRequest Version is the version the client last saw.
+type RestorePresetRevisionRequest struct {
+	PresetId string
+	UserId   string
+	Revision int
+	Version  *int
+}
+
+type DiffPresetRevisionsRequest struct {
+	PresetId string
+	UserId   string
+	From     int
+	To       int
+}
+
+type PresetRevisionDiff struct {
+	PresetId string
+	From     int
+	To       int
+	Changes  []PresetModelFieldDiff
+}
+
+type PresetRevisionService interface {
+	FindRevisions(CheckPresetOwnerRequest) ([]repository.PresetRevision, error)
+	FindRevision(PresetRevisionRequest) (*repository.PresetRevision, error)
+	DiffRevisions(DiffPresetRevisionsRequest) (*PresetRevisionDiff, error)
+	RestoreRevision(RestorePresetRevisionRequest) (*repository.RoPreset, error)
+}
@@ -0,0 +1,183 @@
+package service
+
+import (
+	"fmt"
+	"ro-backend/appError"
+	"ro-backend/repository"
+)
+
+func NewPresetRevisionService(pRepo repository.RoPresetRepository, rRepo repository.PresetRevisionRepository, fRepo repository.PresetFolderRepository, validator PresetValidator) PresetRevisionService {
+	return presetRevisionService{pRepo: pRepo, rRepo: rRepo, fRepo: fRepo, validator: validator}
+}
+
+type presetRevisionService struct {
+	pRepo     repository.RoPresetRepository
+	rRepo     repository.PresetRevisionRepository
+	fRepo     repository.PresetFolderRepository
+	validator PresetValidator
+}
+
+func (s presetRevisionService) ValidatePresetOwner(r CheckPresetOwnerRequest) (*repository.RoPreset, error) {
+	res, err := s.pRepo.FindPresetById(repository.FindPresetByIdInput{
+		Id:           r.Id,
+		InCludeModel: false,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if res.UserId != r.UserId {
+		return nil, fmt.Errorf(appError.ErrNotMyPreset)
+	}
+
+	return res, nil
+}
+
+func (s presetRevisionService) FindRevisions(r CheckPresetOwnerRequest) ([]repository.PresetRevision, error) {
+	_, err := s.ValidatePresetOwner(r)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.rRepo.FindRevisionsByPresetId(r.Id)
+}
+
+func (s presetRevisionService) FindRevision(r PresetRevisionRequest) (*repository.PresetRevision, error) {
+	_, err := s.ValidatePresetOwner(CheckPresetOwnerRequest{Id: r.PresetId, UserId: r.UserId})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.rRepo.FindRevision(repository.FindPresetRevisionInput{
+		PresetId: r.PresetId,
+		Revision: r.Revision,
+	})
+}
+
+func (s presetRevisionService) DiffRevisions(r DiffPresetRevisionsRequest) (*PresetRevisionDiff, error) {
+	_, err := s.ValidatePresetOwner(CheckPresetOwnerRequest{Id: r.PresetId, UserId: r.UserId})
+	if err != nil {
+		return nil, err
+	}
+
+	from, err := s.rRepo.FindRevision(repository.FindPresetRevisionInput{
+		PresetId: r.PresetId,
+		Revision: r.From,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	to, err := s.rRepo.FindRevision(repository.FindPresetRevisionInput{
+		PresetId: r.PresetId,
+		Revision: r.To,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &PresetRevisionDiff{
+		PresetId: r.PresetId,
+		From:     from.Revision,
+		To:       to.Revision,
+		Changes:  DiffPresetModel(from.Model, to.Model),
+	}, nil
+}
+
+// RestoreRevision is an update of the model, it needs the expected version and a model that passes
+// the same rules as UpdatePreset, older revisions may have been saved before a rule existed.
+func (s presetRevisionService) RestoreRevision(r RestorePresetRevisionRequest) (*repository.RoPreset, error) {
+	p, err := s.ValidatePresetOwner(CheckPresetOwnerRequest{Id: r.PresetId, UserId: r.UserId})
+	if err != nil {
+		return nil, err
+	}
+
+	err = checkPresetVersion(p, r.Version)
+	if err != nil {
+		return nil, err
+	}
+
+	revision, err := s.rRepo.FindRevision(repository.FindPresetRevisionInput{
+		PresetId: r.PresetId,
+		Revision: r.Revision,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	errs, err := validatePresetModel(s.validator, revision.Model, "model.")
+	if err != nil {
+		return nil, err
+	}
+
+	budget := CalcStatBudget(revision.Model)
+	errs = append(errs, statBudgetErrors(budget, "model.")...)
+	if len(errs) > 0 {
+		return nil, &appError.ValidationError{Errors: errs}
+	}
+
+	err = checkFolderClass(s.fRepo, *p, revision.Model.Class)
+	if err != nil {
+		return nil, err
+	}
+
+	err = recordPresetRevision(s.pRepo, s.rRepo, *p, revision.Model, r.UserId, func() error {
+		return s.pRepo.UpdatePreset(p.Id, repository.UpdatePresetInput{
+			Model:         &revision.Model,
+			StatOverspent: &budget.Overspent,
+			Version:       r.Version,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.pRepo.FindPresetById(repository.FindPresetByIdInput{
+		Id:           p.Id,
+		InCludeModel: false,
+	})
+}
+
+// recordPresetRevision runs the model update and stores the new model as the next revision.
+// Presets created before revisions existed get their current model stored first,
+// so the very first update can still be restored.
+func recordPresetRevision(pRepo repository.RoPresetRepository, rRepo repository.PresetRevisionRepository, p repository.RoPreset, model repository.PresetModel, userId string, update func() error) error {
+	total, err := rRepo.CountRevisionsByPresetId(p.Id)
+	if err != nil {
+		return err
+	}
+
+	if total == 0 {
+		current, err := pRepo.FindPresetById(repository.FindPresetByIdInput{
+			Id:           p.Id,
+			InCludeModel: true,
+		})
+		if err != nil {
+			return err
+		}
+
+		_, err = rRepo.CreateRevision(repository.CreatePresetRevisionInput{
+			PresetId: current.Id,
+			UserId:   current.UserId,
+			Label:    current.Label,
+			Model:    current.Model,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	err = update()
+	if err != nil {
+		return err
+	}
+
+	_, err = rRepo.CreateRevision(repository.CreatePresetRevisionInput{
+		PresetId: p.Id,
+		UserId:   userId,
+		Label:    p.Label,
+		Model:    model,
+	})
+
+	return err
+}
@@ -0,0 +1,116 @@
+package service
+
+import (
+	"errors"
+	"ro-backend/appError"
+	"ro-backend/configuration"
+	"ro-backend/repository"
+	"testing"
+)
+
+type restorePresetRepo struct {
+	repository.RoPresetRepository
+	preset  repository.RoPreset
+	updated bool
+}
+
+func (r *restorePresetRepo) FindPresetById(repository.FindPresetByIdInput) (*repository.RoPreset, error) {
+	p := r.preset
+
+	return &p, nil
+}
+
+func (r *restorePresetRepo) UpdatePreset(id string, i repository.UpdatePresetInput) error {
+	r.updated = true
+
+	return nil
+}
+
+type restoreRevisionRepo struct {
+	repository.PresetRevisionRepository
+	revision repository.PresetRevision
+}
+
+func (r restoreRevisionRepo) FindRevision(repository.FindPresetRevisionInput) (*repository.PresetRevision, error) {
+	revision := r.revision
+
+	return &revision, nil
+}
+
+func (r restoreRevisionRepo) CountRevisionsByPresetId(string) (int, error) {
+	return 1, nil
+}
+
+func (r restoreRevisionRepo) CreateRevision(i repository.CreatePresetRevisionInput) (*repository.PresetRevision, error) {
+	return &repository.PresetRevision{PresetId: i.PresetId, Model: i.Model}, nil
+}
+
+func newRestoreTest(model repository.PresetModel) (PresetRevisionService, *restorePresetRepo) {
+	configuration.Config = &configuration.AppConfig{}
+
+	pRepo := &restorePresetRepo{preset: repository.RoPreset{Id: "p1", UserId: "u1", ClassId: 7, Version: 3}}
+	rRepo := restoreRevisionRepo{revision: repository.PresetRevision{PresetId: "p1", Revision: 1, Model: model}}
+
+	return NewPresetRevisionService(pRepo, rRepo, nil, NewPresetValidator(emptyItemRepo{})), pRepo
+}
+
+func TestRestoreRevisionVersion(t *testing.T) {
+	stale := 2
+	current := 3
+
+	tests := []struct {
+		name     string
+		version  *int
+		conflict bool
+		want     string
+	}{
+		{"no version", nil, false, appError.ErrPresetVersionRequired},
+		{"stale version", &stale, true, ""},
+		{"current version", &current, false, ""},
+	}
+
+	for _, tt := range tests {
+		s, pRepo := newRestoreTest(validPresetModel(7, 99))
+		_, err := s.RestoreRevision(RestorePresetRevisionRequest{PresetId: "p1", UserId: "u1", Revision: 1, Version: tt.version})
+
+		var conflictErr *appError.VersionConflictError
+		switch {
+		case tt.conflict:
+			if !errors.As(err, &conflictErr) || conflictErr.CurrentVersion != current {
+				t.Errorf("%v: err = %v, want a conflict at version %v", tt.name, err, current)
+			}
+		case tt.want != "":
+			if err == nil || err.Error() != tt.want {
+				t.Errorf("%v: err = %v, want %v", tt.name, err, tt.want)
+			}
+		case err != nil:
+			t.Errorf("%v: err = %v, want nil", tt.name, err)
+		}
+
+		if wantUpdated := err == nil; pRepo.updated != wantUpdated {
+			t.Errorf("%v: updated = %v, want %v", tt.name, pRepo.updated, wantUpdated)
+		}
+	}
+}
+
+func TestRestoreRevisionInvalidModel(t *testing.T) {
+	version := 3
+
+	// a normal class over level 99 with trait stats, saved before the rules existed
+	model := validPresetModel(7, 150)
+	model.Pow = 10
+
+	s, pRepo := newRestoreTest(model)
+	_, err := s.RestoreRevision(RestorePresetRevisionRequest{PresetId: "p1", UserId: "u1", Revision: 1, Version: &version})
+
+	var validationErr *appError.ValidationError
+	if !errors.As(err, &validationErr) {
+		t.Fatalf("err = %v, want a validation error", err)
+	}
+	if len(validationErr.Errors) != 2 {
+		t.Errorf("errors = %+v, want level and pow", validationErr.Errors)
+	}
+	if pRepo.updated {
+		t.Error("the preset was updated")
+	}
+}
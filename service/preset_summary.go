@@ -2,6 +2,7 @@ package service
 
 type RankingSummary struct {
 	ItemId       int
+	ItemName     string
 	UsingRate    float64
 	TotalPreset  int
 	TotalAccount int
@@ -15,10 +16,21 @@ type UsingSkillSummary = map[string]ItemPositionSummary
 type JobSummary = map[int]UsingSkillSummary
 type AllSummary = map[string]JobSummary
 
+// ItemOptionRanking is how many presets of a class have a random option on a slot,
+// AvgValue is the average value of the option in those presets.
+type ItemOptionRanking struct {
+	Option      string
+	TotalPreset int
+	UsingRate   float64
+	AvgValue    float64
+}
+
 type PresetSummary struct {
 	SummaryClassSkillMap map[int]map[string]int
 	TotalSelectedJobMap  map[int]int
 	JobSummary           map[int]map[string]map[string][]RankingSummary
+	// jobId -> slot -> options, options without a slot are under "any"
+	ItemOptionSummary map[int]map[string][]ItemOptionRanking
 }
 
 type PresetSummaryService interface {
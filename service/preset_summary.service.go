@@ -13,12 +13,13 @@ import (
 	"strings"
 )
 
-func NewSummaryPresetService(pRepo repository.RoPresetRepository) PresetSummaryService {
-	return summaryPresetService{pRepo: pRepo}
+func NewSummaryPresetService(pRepo repository.RoPresetRepository, itemRepo repository.ItemRepository) PresetSummaryService {
+	return summaryPresetService{pRepo: pRepo, itemRepo: itemRepo}
 }
 
 type summaryPresetService struct {
-	pRepo repository.RoPresetRepository
+	pRepo    repository.RoPresetRepository
+	itemRepo repository.ItemRepository
 }
 
 type EnchantSummary struct {
@@ -31,6 +32,16 @@ type ItemSummary struct {
 	Enchants map[string]int
 }
 
+const itemOptionAnySlot = "any"
+
+type itemOptionUsage struct {
+	TotalPreset int
+	TotalValue  int
+}
+
+// jobId -> slot -> option
+type itemOptionUsageSummary = map[int]map[string]map[string]*itemOptionUsage
+
 func (s summaryPresetService) GenerateSummary() (*PresetSummary, error) {
 	skip := int(0)
 	take := int(1000)
@@ -50,6 +61,8 @@ func (s summaryPresetService) GenerateSummary() (*PresetSummary, error) {
 	userDataMap := AllSummary{}
 	presetSummaryMap := map[int]map[string]int{}
 	setSummary(&userDataMap, res.Items, &presetSummaryMap)
+	itemOptionUsages := itemOptionUsageSummary{}
+	setItemOptionSummary(itemOptionUsages, res.Items)
 
 	total := int64(res.Total)
 	round := int(math.Ceil(float64(total) / float64(take)))
@@ -67,6 +80,7 @@ func (s summaryPresetService) GenerateSummary() (*PresetSummary, error) {
 		fmt.Printf("Round %v - %v passed\n", i, skip)
 
 		setSummary(&userDataMap, res.Items, &presetSummaryMap)
+		setItemOptionSummary(itemOptionUsages, res.Items)
 	}
 
 	type UsingItemFrequency struct {
@@ -181,18 +195,127 @@ func (s summaryPresetService) GenerateSummary() (*PresetSummary, error) {
 		}
 	}
 
+	err = s.setItemNames(jobSummary)
+	if err != nil {
+		return nil, err
+	}
+
 	writeJsonFile(presetSummaryMap, "x_presetSummaryMap.json")
 	writeJsonFile(summaryClassSkillMap, "x_summaryClassSkillMap.json")
 	writeJsonFile(totalSelectedJobMap, "x_totalSelectedJobMap.json")
 	writeJsonFile(jobSummary, "x.json")
 
+	itemOptionSummary := rankItemOptions(itemOptionUsages, presetSummaryMap, totalRanking)
+
 	return &PresetSummary{
 		SummaryClassSkillMap: summaryClassSkillMap,
 		TotalSelectedJobMap:  totalSelectedJobMap,
 		JobSummary:           jobSummary,
+		ItemOptionSummary:    itemOptionSummary,
 	}, nil
 }
 
+// setItemOptionSummary counts a preset once per slot and option, however many times it has the option.
+func setItemOptionSummary(summary itemOptionUsageSummary, presets []repository.RoPreset) {
+	for _, preset := range presets {
+		options, _ := preset.Model.ItemOptions()
+		counted := map[string]bool{}
+		for _, v := range options {
+			slot := v.Slot
+			if slot == "" {
+				slot = itemOptionAnySlot
+			}
+
+			if summary[preset.ClassId] == nil {
+				summary[preset.ClassId] = map[string]map[string]*itemOptionUsage{}
+			}
+			if summary[preset.ClassId][slot] == nil {
+				summary[preset.ClassId][slot] = map[string]*itemOptionUsage{}
+			}
+			if summary[preset.ClassId][slot][v.Option] == nil {
+				summary[preset.ClassId][slot][v.Option] = &itemOptionUsage{}
+			}
+
+			usage := summary[preset.ClassId][slot][v.Option]
+			usage.TotalValue += v.Value
+			if key := slot + ":" + v.Option; !counted[key] {
+				counted[key] = true
+				usage.TotalPreset += 1
+			}
+		}
+	}
+}
+
+// rankItemOptions keeps the most used options of every slot, the rate is against every preset of the class.
+func rankItemOptions(summary itemOptionUsageSummary, presetSummaryMap map[int]map[string]int, totalRanking int) map[int]map[string][]ItemOptionRanking {
+	res := map[int]map[string][]ItemOptionRanking{}
+	for jobId, slotMap := range summary {
+		totalPreset := 0
+		for _, total := range presetSummaryMap[jobId] {
+			totalPreset += total
+		}
+
+		res[jobId] = map[string][]ItemOptionRanking{}
+		for slot, optionMap := range slotMap {
+			rankings := []ItemOptionRanking{}
+			for option, usage := range optionMap {
+				rankings = append(rankings, ItemOptionRanking{
+					Option:      option,
+					TotalPreset: usage.TotalPreset,
+					UsingRate:   float64(usage.TotalPreset) / float64(totalPreset),
+					AvgValue:    float64(usage.TotalValue) / float64(usage.TotalPreset),
+				})
+			}
+			slices.SortFunc(rankings, func(a, b ItemOptionRanking) int {
+				return cmp.Compare(a.TotalPreset, b.TotalPreset) * -1
+			})
+			if len(rankings) > totalRanking {
+				rankings = rankings[:totalRanking]
+			}
+
+			res[jobId][slot] = rankings
+		}
+	}
+
+	return res
+}
+
+func (s summaryPresetService) setItemNames(jobSummary map[int]map[string]map[string][]RankingSummary) error {
+	itemIds := []int{}
+	for _, skillMap := range jobSummary {
+		for _, positionMap := range skillMap {
+			for _, rankings := range positionMap {
+				for _, v := range rankings {
+					itemIds = append(itemIds, v.ItemId)
+				}
+			}
+		}
+	}
+	slices.Sort(itemIds)
+	itemIds = slices.Compact(itemIds)
+
+	items, err := s.itemRepo.FindItemsByIds(itemIds)
+	if err != nil {
+		return err
+	}
+	itemNames := map[int]string{}
+	for _, v := range items {
+		itemNames[v.Id] = v.Name
+	}
+
+	for _, skillMap := range jobSummary {
+		for _, positionMap := range skillMap {
+			for _, rankings := range positionMap {
+				for i := range rankings {
+					rankings[i].ItemName = itemNames[rankings[i].ItemId]
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
 func setSummary(summary *AllSummary, presets []repository.RoPreset, presetSummary *map[int]map[string]int) {
 	for _, preset := range presets {
 		skillName := getSkillName(preset.Model.SelectedAtkSkill)
@@ -2,6 +2,7 @@ package service
 
 import (
 	"ro-backend/repository"
+	"time"
 )
 
 type DeleteTagInput struct {
@@ -46,6 +47,22 @@ type BulkOperationInput struct {
 	DeleteTags  []string
 }
 
+type FindMyLikesRequest struct {
+	UserId string
+	Skip   int
+	Take   int
+}
+
+type LikedPresetWithTags struct {
+	PresetWithTags
+	LikedAt time.Time
+}
+
+type MyLikesResult struct {
+	Items []LikedPresetWithTags
+	Total int
+}
+
 type PresetTagService interface {
 	CreateTags(repository.CreateTagInput) (*PresetWithTags, error)
 	BulkOperationTags(BulkOperationInput) (*PresetWithTags, error)
@@ -54,4 +71,6 @@ type PresetTagService interface {
 	UnLikeTag(repository.LikeTagInput) (*repository.PresetTag, error)
 	PartialSearchTags(repository.PartialSearchTagsInput, PartialSearchMetaInput) (*PartialSearchTagsResult, error)
 	AttachTags(userId string, p []repository.RoPreset) ([]PresetWithTags, error)
+	MigrateLikes() (int, error)
+	FindMyLikes(FindMyLikesRequest) (*MyLikesResult, error)
 }
@@ -5,16 +5,18 @@ import (
 	"ro-backend/appError"
 	"ro-backend/repository"
 	"slices"
+	"time"
 )
 
-func NewPresetTagService(tRepo repository.PresetTagRepository, pRepo repository.RoPresetRepository, userRepo repository.UserRepository) PresetTagService {
-	return presetTagService{tRepo: tRepo, pRepo: pRepo, userRepo: userRepo}
+func NewPresetTagService(tRepo repository.PresetTagRepository, pRepo repository.RoPresetRepository, userRepo repository.UserRepository, registry PresetTagRegistryService) PresetTagService {
+	return presetTagService{tRepo: tRepo, pRepo: pRepo, userRepo: userRepo, registry: registry}
 }
 
 type presetTagService struct {
 	pRepo    repository.RoPresetRepository
 	tRepo    repository.PresetTagRepository
 	userRepo repository.UserRepository
+	registry PresetTagRegistryService
 }
 
 func (s presetTagService) ValidatePresetOwner(r CheckPresetOwnerRequest) (*repository.RoPreset, error) {
@@ -33,6 +35,15 @@ func (s presetTagService) ValidatePresetOwner(r CheckPresetOwnerRequest) (*repos
 	return res, nil
 }
 
+// findLikedTagIds is empty for anonymous readers of public presets.
+func (s presetTagService) findLikedTagIds(userId string, tagIds []string) ([]string, error) {
+	if userId == "" || len(tagIds) == 0 {
+		return []string{}, nil
+	}
+
+	return s.tRepo.FindLikedTagIds(userId, tagIds)
+}
+
 func (s presetTagService) AttachTags(userId string, presets []repository.RoPreset) ([]PresetWithTags, error) {
 	presetIds := []string{}
 	for _, v := range presets {
@@ -44,6 +55,15 @@ func (s presetTagService) AttachTags(userId string, presets []repository.RoPrese
 		return nil, err
 	}
 
+	tagIds := []string{}
+	for _, v := range tgs {
+		tagIds = append(tagIds, v.Id)
+	}
+	liked, err := s.findLikedTagIds(userId, tagIds)
+	if err != nil {
+		return nil, err
+	}
+
 	presetTagsMap := map[string][]TagWithLiked{}
 	for _, v := range tgs {
 		if presetTagsMap[v.PresetId] == nil {
@@ -51,7 +71,7 @@ func (s presetTagService) AttachTags(userId string, presets []repository.RoPrese
 		}
 		presetTagsMap[v.PresetId] = append(presetTagsMap[v.PresetId], TagWithLiked{
 			PresetTag: v,
-			Liked:     v.IsILike(userId),
+			Liked:     slices.Contains(liked, v.Id),
 		})
 	}
 
@@ -66,6 +86,16 @@ func (s presetTagService) AttachTags(userId string, presets []repository.RoPrese
 	return presetTags, nil
 }
 
+// publishedClassId is the class tags are searched by, a draft that changed class keeps its tags
+// on the published class until it is republished.
+func publishedClassId(p repository.RoPreset) int {
+	if p.Published != nil {
+		return p.Published.ClassId
+	}
+
+	return p.ClassId
+}
+
 func (s presetTagService) CreateTags(i repository.CreateTagInput) (*PresetWithTags, error) {
 	p, err := s.ValidatePresetOwner(CheckPresetOwnerRequest{
 		Id:     i.PresetId,
@@ -78,7 +108,17 @@ func (s presetTagService) CreateTags(i repository.CreateTagInput) (*PresetWithTa
 		return nil, fmt.Errorf(appError.ErrCannotTagUnpublished)
 	}
 
-	i.ClassId = p.ClassId
+	err = checkUserActive(s.userRepo, i.PublisherId)
+	if err != nil {
+		return nil, err
+	}
+
+	i.Tags, err = s.registry.NormalizeTags(i.Tags)
+	if err != nil {
+		return nil, err
+	}
+
+	i.ClassId = publishedClassId(*p)
 	_, err = s.tRepo.CreateTags(i)
 	if err != nil {
 		return nil, err
@@ -104,6 +144,22 @@ func (s presetTagService) BulkOperationTags(i BulkOperationInput) (*PresetWithTa
 		return nil, fmt.Errorf(appError.ErrCannotTagUnpublished)
 	}
 
+	err = checkUserActive(s.userRepo, i.PublisherId)
+	if err != nil {
+		return nil, err
+	}
+
+	createSlugs, err := s.registry.NormalizeTags(i.CreateTags)
+	if err != nil {
+		return nil, err
+	}
+
+	// tags saved before the registry may not be canonical, delete by the given tag or its canonical slug
+	deleteSlugs, err := s.registry.CanonicalTags(i.DeleteTags)
+	if err != nil {
+		return nil, err
+	}
+
 	tags, err := s.tRepo.FindTagsByPresetId(p.Id)
 	if err != nil {
 		return nil, err
@@ -116,24 +172,24 @@ func (s presetTagService) BulkOperationTags(i BulkOperationInput) (*PresetWithTa
 
 	createTags := repository.CreateTagInput{
 		PublisherId: i.PublisherId,
-		ClassId:     p.ClassId,
+		ClassId:     publishedClassId(*p),
 		PresetId:    p.Id,
 		Tags:        []string{},
 	}
-	for _, v := range i.CreateTags {
+	for _, v := range createSlugs {
 		if _, found := tagMap[v]; !found {
 			createTags.Tags = append(createTags.Tags, v)
 		}
 	}
 
 	deleteTagIds := []string{}
-	for _, v := range i.DeleteTags {
-		if tag, found := tagMap[v]; found {
+	for _, v := range append(i.DeleteTags, deleteSlugs...) {
+		if tag, found := tagMap[v]; found && !slices.Contains(deleteTagIds, tag.Id) {
 			deleteTagIds = append(deleteTagIds, tag.Id)
 		}
 	}
 
-	i.ClassId = p.ClassId
+	i.ClassId = createTags.ClassId
 	err = s.tRepo.BulkOperationTags(createTags, deleteTagIds)
 	if err != nil {
 		return nil, err
@@ -173,21 +229,38 @@ func (s presetTagService) DeleteTag(i DeleteTagInput) (*PresetWithTags, error) {
 }
 
 func (s presetTagService) LikeTag(i repository.LikeTagInput) (*repository.PresetTag, error) {
-	tag, err := s.tRepo.FindTagById(i.Id)
+	_, err := s.tRepo.FindTagById(i.Id)
 	if err != nil {
 		return nil, err
 	}
-	if slices.Contains(tag.Likes, i.UserId) {
-		return tag, nil
+
+	err = checkUserActive(s.userRepo, i.UserId)
+	if err != nil {
+		return nil, err
 	}
 
-	tag.TotalLike = tag.TotalLike + 1
-	i.TotalLike = tag.TotalLike
+	_, err = s.tRepo.LikeTag(i)
+	if err != nil {
+		return nil, err
+	}
 
-	return tag, s.tRepo.LikeTag(i)
+	// read again for the count after the $inc
+	return s.tRepo.FindTagById(i.Id)
 }
 
 func (s presetTagService) PartialSearchTags(i repository.PartialSearchTagsInput, si PartialSearchMetaInput) (*PartialSearchTagsResult, error) {
+	if i.Tag != "" {
+		tags, err := s.registry.CanonicalTags([]string{i.Tag})
+		if err != nil {
+			return nil, err
+		}
+		// tags not normalized by NormalizeExistingTags yet are still found by the query as given
+		if tags[0] != i.Tag {
+			i.TagAliases = []string{i.Tag}
+		}
+		i.Tag = tags[0]
+	}
+
 	tags, err := s.tRepo.PartialSearchTags(i, si.Skip, si.Limit)
 	if err != nil {
 		return nil, err
@@ -204,6 +277,8 @@ func (s presetTagService) PartialSearchTags(i repository.PartialSearchTagsInput,
 	}
 	presetMap := map[string]repository.RoPreset{}
 	for _, v := range presets {
+		// tagged presets are published, show the published copy instead of the draft
+		v.UsePublishedSnapshot()
 		presetMap[v.Id] = v
 	}
 
@@ -217,7 +292,16 @@ func (s presetTagService) PartialSearchTags(i repository.PartialSearchTagsInput,
 		if presetTagsMap[v.PresetId] == nil {
 			presetTagsMap[v.PresetId] = map[string]int{}
 		}
-		presetTagsMap[v.PresetId][v.Tag] = len(v.Likes)
+		presetTagsMap[v.PresetId][v.Tag] = v.TotalLike
+	}
+
+	tagIds := []string{}
+	for _, v := range tags.Items {
+		tagIds = append(tagIds, v.Id)
+	}
+	liked, err := s.findLikedTagIds(si.UserId, tagIds)
+	if err != nil {
+		return nil, err
 	}
 
 	presetTags := []PresetTag{}
@@ -226,7 +310,7 @@ func (s presetTagService) PartialSearchTags(i repository.PartialSearchTagsInput,
 			RoPreset: presetMap[v.PresetId],
 			TagId:    v.Id,
 			Tags:     presetTagsMap[v.PresetId],
-			Liked:    v.IsILike(si.UserId),
+			Liked:    slices.Contains(liked, v.Id),
 		})
 	}
 
@@ -237,16 +321,76 @@ func (s presetTagService) PartialSearchTags(i repository.PartialSearchTagsInput,
 }
 
 func (s presetTagService) UnLikeTag(i repository.LikeTagInput) (*repository.PresetTag, error) {
-	tag, err := s.tRepo.FindTagById(i.Id)
+	_, err := s.tRepo.FindTagById(i.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	err = checkUserActive(s.userRepo, i.UserId)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = s.tRepo.UnLikeTag(i)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.tRepo.FindTagById(i.Id)
+}
+
+func (s presetTagService) MigrateLikes() (int, error) {
+	return s.tRepo.MigrateLikes()
+}
+
+// FindMyLikes reads the liked presets and their tags in one query each, whatever the page size.
+func (s presetTagService) FindMyLikes(r FindMyLikesRequest) (*MyLikesResult, error) {
+	liked, err := s.tRepo.FindLikedPresets(r.UserId, r.Skip, r.Take)
 	if err != nil {
 		return nil, err
 	}
-	if !slices.Contains(tag.Likes, i.UserId) {
-		return tag, nil
+
+	presetIds := []string{}
+	for _, v := range liked.Items {
+		presetIds = append(presetIds, v.PresetId)
+	}
+
+	presets, err := s.pRepo.FindPresetByIds(presetIds)
+	if err != nil {
+		return nil, err
+	}
+	presetMap := map[string]repository.RoPreset{}
+	for _, v := range presets {
+		v.UsePublishedSnapshot()
+		presetMap[v.Id] = v
+	}
+
+	ordered := []repository.RoPreset{}
+	likedAt := []time.Time{}
+	for _, v := range liked.Items {
+		p, found := presetMap[v.PresetId]
+		if !found || !p.IsPublished {
+			continue
+		}
+		ordered = append(ordered, p)
+		likedAt = append(likedAt, v.LikedAt)
 	}
 
-	tag.TotalLike = tag.TotalLike - 1
-	i.TotalLike = tag.TotalLike
+	withTags, err := s.AttachTags(r.UserId, ordered)
+	if err != nil {
+		return nil, err
+	}
+
+	res := MyLikesResult{
+		Items: []LikedPresetWithTags{},
+		Total: liked.Total,
+	}
+	for i, v := range withTags {
+		res.Items = append(res.Items, LikedPresetWithTags{
+			PresetWithTags: v,
+			LikedAt:        likedAt[i],
+		})
+	}
 
-	return tag, s.tRepo.UnLikeTag(i)
+	return &res, nil
 }
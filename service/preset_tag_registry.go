@@ -0,0 +1,57 @@
+package service
+
+import "ro-backend/repository"
+
+type SavePresetTagRequest struct {
+	Slug     string
+	NameEn   string
+	NameTh   string
+	Synonyms []string
+	Banned   bool
+}
+
+type RenamePresetTagRequest struct {
+	Slug string
+	To   string
+}
+
+// MergePresetTagsRequest From may name synonyms, the whole tag they belong to is merged.
+type MergePresetTagsRequest struct {
+	From []string
+	To   string
+}
+
+// MergePresetTagsResult Rewritten is the number of preset tags moved to the tag.
+type MergePresetTagsResult struct {
+	Tag       repository.PresetTagDefinition
+	Rewritten int
+}
+
+// SuggestPresetTagsRequest ClassId 0 suggests tags of every class.
+type SuggestPresetTagsRequest struct {
+	Query   string
+	ClassId int
+	Limit   int
+}
+
+type PresetTagSuggestion struct {
+	Slug        string
+	NameEn      string
+	NameTh      string
+	TotalPreset int
+	TotalLike   int
+}
+
+type PresetTagRegistryService interface {
+	FindTags() ([]repository.PresetTagDefinition, error)
+	// NormalizeTags gives the canonical slugs of tags a user adds, banned and invalid tags are an error.
+	NormalizeTags(tags []string) ([]string, error)
+	// CanonicalTags gives the canonical slugs of tags a user looks for.
+	CanonicalTags(tags []string) ([]string, error)
+	SaveTag(SavePresetTagRequest) (*repository.PresetTagDefinition, error)
+	RenameTag(RenamePresetTagRequest) (*MergePresetTagsResult, error)
+	MergeTags(MergePresetTagsRequest) (*MergePresetTagsResult, error)
+	// NormalizeExistingTags rewrites tags saved before the registry to their canonical slug.
+	NormalizeExistingTags() (int, error)
+	SuggestTags(SuggestPresetTagsRequest) ([]PresetTagSuggestion, error)
+}
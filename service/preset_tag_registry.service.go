@@ -0,0 +1,376 @@
+package service
+
+import (
+	"fmt"
+	"ro-backend/appError"
+	"ro-backend/repository"
+	"slices"
+	"strings"
+	"unicode/utf8"
+)
+
+const maxTagLength = 30
+
+func NewPresetTagRegistryService(definitionRepo repository.PresetTagDefinitionRepository, tagRepo repository.PresetTagRepository) PresetTagRegistryService {
+	return presetTagRegistryService{
+		definitionRepo: definitionRepo,
+		tagRepo:        tagRepo,
+	}
+}
+
+type presetTagRegistryService struct {
+	definitionRepo repository.PresetTagDefinitionRepository
+	tagRepo        repository.PresetTagRepository
+}
+
+type tagVocabulary struct {
+	definitions []repository.PresetTagDefinition
+	// canonical maps slugs and synonyms to the slug of their definition
+	canonical map[string]string
+	banned    map[string]bool
+}
+
+func (v tagVocabulary) isRegistered(slug string) bool {
+	return v.canonical[slug] == slug
+}
+
+// canonicalOf keeps unknown tags as their slug, the registry does not have to know every tag.
+func (v tagVocabulary) canonicalOf(tag string) string {
+	slug := repository.NormalizeTagSlug(tag)
+	if canonical, found := v.canonical[slug]; found {
+		return canonical
+	}
+
+	return slug
+}
+
+func validTagSlug(slug string) bool {
+	return slug != "" && utf8.RuneCountInString(slug) <= maxTagLength
+}
+
+// appendTagSlugs appends slugs not in list yet.
+func appendTagSlugs(list []string, slugs ...string) []string {
+	for _, v := range slugs {
+		if !slices.Contains(list, v) {
+			list = append(list, v)
+		}
+	}
+
+	return list
+}
+
+// vocabulary reads the whole registry, it is a few hundred tags at most.
+func (s presetTagRegistryService) vocabulary() (*tagVocabulary, error) {
+	definitions, err := s.definitionRepo.FindTagDefinitions()
+	if err != nil {
+		return nil, err
+	}
+
+	v := tagVocabulary{
+		definitions: definitions,
+		canonical:   map[string]string{},
+		banned:      map[string]bool{},
+	}
+	for _, d := range definitions {
+		v.canonical[d.Slug] = d.Slug
+		for _, synonym := range d.Synonyms {
+			v.canonical[synonym] = d.Slug
+		}
+		if d.Banned {
+			v.banned[d.Slug] = true
+		}
+	}
+
+	return &v, nil
+}
+
+func (s presetTagRegistryService) FindTags() ([]repository.PresetTagDefinition, error) {
+	return s.definitionRepo.FindTagDefinitions()
+}
+
+func (s presetTagRegistryService) NormalizeTags(tags []string) ([]string, error) {
+	v, err := s.vocabulary()
+	if err != nil {
+		return nil, err
+	}
+
+	res := []string{}
+	for _, tag := range tags {
+		slug := v.canonicalOf(tag)
+		if !validTagSlug(slug) {
+			return nil, fmt.Errorf(appError.ErrInvalidTagInput)
+		}
+		if v.banned[slug] {
+			return nil, fmt.Errorf(appError.ErrTagBanned)
+		}
+		res = appendTagSlugs(res, slug)
+	}
+
+	return res, nil
+}
+
+func (s presetTagRegistryService) CanonicalTags(tags []string) ([]string, error) {
+	v, err := s.vocabulary()
+	if err != nil {
+		return nil, err
+	}
+
+	res := []string{}
+	for _, tag := range tags {
+		res = append(res, v.canonicalOf(tag))
+	}
+
+	return res, nil
+}
+
+// findOrNewTag gives an empty definition for a tag that is only used on presets.
+func (s presetTagRegistryService) findOrNewTag(v *tagVocabulary, slug string) (*repository.PresetTagDefinition, error) {
+	if !v.isRegistered(slug) {
+		return &repository.PresetTagDefinition{Slug: slug, Synonyms: []string{}}, nil
+	}
+
+	return s.definitionRepo.FindTagDefinitionBySlug(slug)
+}
+
+// SaveTag creates or updates a tag, presets tagged with one of its synonyms are moved to the tag.
+func (s presetTagRegistryService) SaveTag(r SavePresetTagRequest) (*repository.PresetTagDefinition, error) {
+	slug := repository.NormalizeTagSlug(r.Slug)
+	if !validTagSlug(slug) {
+		return nil, fmt.Errorf(appError.ErrInvalidTagInput)
+	}
+
+	v, err := s.vocabulary()
+	if err != nil {
+		return nil, err
+	}
+	if owner, found := v.canonical[slug]; found && owner != slug {
+		return nil, fmt.Errorf(appError.ErrTagAlreadyExists)
+	}
+
+	synonyms := []string{}
+	for _, synonym := range r.Synonyms {
+		synonym = repository.NormalizeTagSlug(synonym)
+		if !validTagSlug(synonym) {
+			return nil, fmt.Errorf(appError.ErrInvalidTagInput)
+		}
+		if owner, found := v.canonical[synonym]; found && owner != slug {
+			return nil, fmt.Errorf(appError.ErrTagAlreadyExists)
+		}
+		if synonym != slug {
+			synonyms = appendTagSlugs(synonyms, synonym)
+		}
+	}
+
+	err = s.definitionRepo.UpsertTagDefinition(repository.PresetTagDefinition{
+		Slug:     slug,
+		NameEn:   strings.TrimSpace(r.NameEn),
+		NameTh:   strings.TrimSpace(r.NameTh),
+		Synonyms: synonyms,
+		Banned:   r.Banned,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if len(synonyms) > 0 {
+		_, err = s.tagRepo.MergeTags(synonyms, slug)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return s.definitionRepo.FindTagDefinitionBySlug(slug)
+}
+
+// RenameTag keeps the old slug as a synonym, so clients that still send it get the new tag.
+func (s presetTagRegistryService) RenameTag(r RenamePresetTagRequest) (*MergePresetTagsResult, error) {
+	from := repository.NormalizeTagSlug(r.Slug)
+	to := repository.NormalizeTagSlug(r.To)
+	if !validTagSlug(from) || !validTagSlug(to) || from == to {
+		return nil, fmt.Errorf(appError.ErrInvalidTagInput)
+	}
+
+	v, err := s.vocabulary()
+	if err != nil {
+		return nil, err
+	}
+	if owner, found := v.canonical[to]; found && owner != from {
+		return nil, fmt.Errorf(appError.ErrTagAlreadyExists)
+	}
+
+	tag, err := s.findOrNewTag(v, from)
+	if err != nil {
+		return nil, err
+	}
+
+	tag.Slug = to
+	tag.Synonyms = appendTagSlugs(slices.DeleteFunc(tag.Synonyms, func(synonym string) bool {
+		return synonym == to
+	}), from)
+	err = s.definitionRepo.UpsertTagDefinition(*tag)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.definitionRepo.DeleteTagDefinitions([]string{from})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.rewriteTags(to, tag.Synonyms)
+}
+
+// MergeTags folds tags into another one, their slugs and synonyms become synonyms of the tag.
+func (s presetTagRegistryService) MergeTags(r MergePresetTagsRequest) (*MergePresetTagsResult, error) {
+	v, err := s.vocabulary()
+	if err != nil {
+		return nil, err
+	}
+
+	to := v.canonicalOf(r.To)
+	if !validTagSlug(to) {
+		return nil, fmt.Errorf(appError.ErrInvalidTagInput)
+	}
+
+	sources := []string{}
+	for _, from := range r.From {
+		slug := v.canonicalOf(from)
+		if !validTagSlug(slug) {
+			return nil, fmt.Errorf(appError.ErrInvalidTagInput)
+		}
+		if slug != to {
+			sources = appendTagSlugs(sources, slug)
+		}
+	}
+	if len(sources) == 0 {
+		return nil, fmt.Errorf(appError.ErrInvalidTagInput)
+	}
+
+	tag, err := s.findOrNewTag(v, to)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, slug := range sources {
+		source, err := s.findOrNewTag(v, slug)
+		if err != nil {
+			return nil, err
+		}
+		tag.Synonyms = appendTagSlugs(tag.Synonyms, source.Slug)
+		tag.Synonyms = appendTagSlugs(tag.Synonyms, source.Synonyms...)
+	}
+
+	err = s.definitionRepo.DeleteTagDefinitions(sources)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.definitionRepo.UpsertTagDefinition(*tag)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.rewriteTags(to, tag.Synonyms)
+}
+
+func (s presetTagRegistryService) rewriteTags(to string, from []string) (*MergePresetTagsResult, error) {
+	rewritten, err := s.tagRepo.MergeTags(from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	tag, err := s.definitionRepo.FindTagDefinitionBySlug(to)
+	if err != nil {
+		return nil, err
+	}
+
+	return &MergePresetTagsResult{
+		Tag:       *tag,
+		Rewritten: rewritten,
+	}, nil
+}
+
+// NormalizeExistingTags merges the tags of a preset that become the same slug, with their likes.
+func (s presetTagRegistryService) NormalizeExistingTags() (int, error) {
+	v, err := s.vocabulary()
+	if err != nil {
+		return 0, err
+	}
+
+	tags, err := s.tagRepo.FindDistinctTags()
+	if err != nil {
+		return 0, err
+	}
+
+	from := map[string][]string{}
+	for _, tag := range tags {
+		slug := v.canonicalOf(tag)
+		if slug == tag || !validTagSlug(slug) {
+			continue
+		}
+		from[slug] = append(from[slug], tag)
+	}
+
+	total := 0
+	for to, tags := range from {
+		rewritten, err := s.tagRepo.MergeTags(tags, to)
+		if err != nil {
+			return total, err
+		}
+		total += rewritten
+	}
+
+	return total, nil
+}
+
+// SuggestTags also matches tags by their synonyms and display names, banned tags are never suggested.
+func (s presetTagRegistryService) SuggestTags(r SuggestPresetTagsRequest) ([]PresetTagSuggestion, error) {
+	v, err := s.vocabulary()
+	if err != nil {
+		return nil, err
+	}
+
+	prefix := repository.NormalizeTagSlug(r.Query)
+	query := strings.ToLower(strings.TrimSpace(r.Query))
+	names := map[string]repository.PresetTagDefinition{}
+	input := repository.SuggestTagsInput{
+		Prefix:      prefix,
+		Tags:        []string{},
+		ExcludeTags: []string{},
+		ClassId:     r.ClassId,
+		Limit:       r.Limit,
+	}
+	for _, d := range v.definitions {
+		names[d.Slug] = d
+		if d.Banned {
+			input.ExcludeTags = append(input.ExcludeTags, d.Slug)
+			continue
+		}
+
+		matched := query != "" && (strings.Contains(strings.ToLower(d.NameEn), query) || strings.Contains(d.NameTh, query))
+		for _, synonym := range d.Synonyms {
+			matched = matched || strings.HasPrefix(synonym, prefix)
+		}
+		if matched {
+			input.Tags = append(input.Tags, d.Slug)
+		}
+	}
+
+	tags, err := s.tagRepo.SuggestTags(input)
+	if err != nil {
+		return nil, err
+	}
+
+	res := []PresetTagSuggestion{}
+	for _, t := range tags {
+		res = append(res, PresetTagSuggestion{
+			Slug:        t.Tag,
+			NameEn:      names[t.Tag].NameEn,
+			NameTh:      names[t.Tag].NameTh,
+			TotalPreset: t.TotalPreset,
+			TotalLike:   t.TotalLike,
+		})
+	}
+
+	return res, nil
+}
@@ -0,0 +1,40 @@
+package service
+
+import (
+	"ro-backend/repository"
+	"time"
+)
+
+// FindTrendingRequest ClassId 0 finds trending presets of every class, an empty Window is a week.
+type FindTrendingRequest struct {
+	ClassId int
+	Window  string
+	Skip    int
+	Take    int
+}
+
+type TrendingPreset struct {
+	repository.RoPreset
+	Score     float64
+	TotalLike int
+}
+
+type TrendingTag struct {
+	Tag       string
+	Score     float64
+	TotalLike int
+}
+
+type TrendingResult struct {
+	Window  repository.TrendingWindow
+	Presets []TrendingPreset
+	Total   int
+	Tags    []TrendingTag
+	// UpdatedAt is when the scores were computed, zero before the first refresh
+	UpdatedAt time.Time
+}
+
+type PresetTrendingService interface {
+	RefreshScores() (int, error)
+	FindTrending(FindTrendingRequest) (*TrendingResult, error)
+}
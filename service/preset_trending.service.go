@@ -0,0 +1,204 @@
+package service
+
+import (
+	"fmt"
+	"math"
+	"ro-backend/appError"
+	"ro-backend/configuration"
+	"ro-backend/repository"
+	"time"
+)
+
+// trendingGravity is how fast a like fades, as in the Hacker News ranking.
+const trendingGravity = 1.8
+const trendingTagTake = 10
+
+func NewPresetTrendingService(scoreRepo repository.PresetTrendingScoreRepository, tagRepo repository.PresetTagRepository, presetRepo repository.RoPresetRepository) PresetTrendingService {
+	return presetTrendingService{
+		scoreRepo:  scoreRepo,
+		tagRepo:    tagRepo,
+		presetRepo: presetRepo,
+	}
+}
+
+type presetTrendingService struct {
+	scoreRepo  repository.PresetTrendingScoreRepository
+	tagRepo    repository.PresetTagRepository
+	presetRepo repository.RoPresetRepository
+}
+
+// TrendingRefreshInterval is how often the trending scores are computed again.
+func TrendingRefreshInterval() time.Duration {
+	minutes := configuration.Config.Ro.TrendingRefreshMinutes
+	if minutes <= 0 {
+		minutes = 15
+	}
+
+	return time.Duration(minutes) * time.Minute
+}
+
+// likeScore decays a like by its age in hours, a like now is worth 1/2^gravity.
+func likeScore(at, now time.Time) float64 {
+	hours := max(now.Sub(at).Hours(), 0)
+
+	return 1 / math.Pow(hours+2, trendingGravity)
+}
+
+type trendingKey struct {
+	kind    repository.TrendingKind
+	key     string
+	classId int
+}
+
+// RefreshScores computes the scores of every window from the likes of the longest one.
+func (s presetTrendingService) RefreshScores() (int, error) {
+	now := time.Now()
+	likes, err := s.tagRepo.FindLikesSince(now.Add(-repository.TrendingWindows.Month.Duration()))
+	if err != nil {
+		return 0, err
+	}
+
+	// a preset is scored once under the class of its published copy, whatever class its tags had
+	presetIds := []string{}
+	seen := map[string]bool{}
+	for _, like := range likes {
+		if !seen[like.PresetId] {
+			seen[like.PresetId] = true
+			presetIds = append(presetIds, like.PresetId)
+		}
+	}
+	presets, err := s.presetRepo.FindPresetByIds(presetIds)
+	if err != nil {
+		return 0, err
+	}
+	publishedClass := map[string]int{}
+	for _, v := range presets {
+		if v.IsPublished {
+			v.UsePublishedSnapshot()
+			publishedClass[v.Id] = v.ClassId
+		}
+	}
+
+	total := 0
+	for _, window := range []repository.TrendingWindow{repository.TrendingWindows.Day, repository.TrendingWindows.Week, repository.TrendingWindows.Month} {
+		since := now.Add(-window.Duration())
+		scores := map[trendingKey]*repository.PresetTrendingScore{}
+		add := func(k trendingKey, score float64) {
+			if scores[k] == nil {
+				scores[k] = &repository.PresetTrendingScore{
+					Kind:      k.kind,
+					Key:       k.key,
+					ClassId:   k.classId,
+					Window:    window,
+					UpdatedAt: now,
+				}
+			}
+			scores[k].Score += score
+			scores[k].TotalLike++
+		}
+
+		for _, like := range likes {
+			if like.At.Before(since) {
+				continue
+			}
+
+			score := likeScore(like.At, now)
+			if classId, found := publishedClass[like.PresetId]; found {
+				add(trendingKey{repository.TrendingKinds.Preset, like.PresetId, classId}, score)
+			}
+			add(trendingKey{repository.TrendingKinds.Tag, like.Tag, like.ClassId}, score)
+			add(trendingKey{repository.TrendingKinds.Tag, like.Tag, 0}, score)
+		}
+
+		res := []repository.PresetTrendingScore{}
+		for _, v := range scores {
+			res = append(res, *v)
+		}
+
+		err = s.scoreRepo.ReplaceScores(window, res, now)
+		if err != nil {
+			return total, err
+		}
+		total += len(res)
+	}
+
+	return total, nil
+}
+
+func (s presetTrendingService) FindTrending(r FindTrendingRequest) (*TrendingResult, error) {
+	window := repository.TrendingWindow(r.Window)
+	if r.Window == "" {
+		window = repository.TrendingWindows.Week
+	}
+	if !window.IsValid() || r.ClassId < 0 {
+		return nil, fmt.Errorf(appError.ErrBadInput)
+	}
+
+	presetScores, err := s.scoreRepo.FindScores(repository.FindTrendingScoresInput{
+		Kind:    repository.TrendingKinds.Preset,
+		Window:  window,
+		ClassId: r.ClassId,
+		Skip:    r.Skip,
+		Take:    r.Take,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	tagScores, err := s.scoreRepo.FindScores(repository.FindTrendingScoresInput{
+		Kind:    repository.TrendingKinds.Tag,
+		Window:  window,
+		ClassId: r.ClassId,
+		Take:    trendingTagTake,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	res := TrendingResult{
+		Window:  window,
+		Presets: []TrendingPreset{},
+		Total:   presetScores.Total,
+		Tags:    []TrendingTag{},
+	}
+
+	presetIds := []string{}
+	for _, v := range presetScores.Items {
+		presetIds = append(presetIds, v.Key)
+		res.UpdatedAt = v.UpdatedAt
+	}
+
+	presets, err := s.presetRepo.FindPresetByIds(presetIds)
+	if err != nil {
+		return nil, err
+	}
+	presetMap := map[string]repository.RoPreset{}
+	for _, v := range presets {
+		v.UsePublishedSnapshot()
+		presetMap[v.Id] = v
+	}
+
+	// the scores only hold published presets, one unpublished between both reads is left out
+	for _, v := range presetScores.Items {
+		p, found := presetMap[v.Key]
+		if !found || !p.IsPublished {
+			continue
+		}
+		res.Presets = append(res.Presets, TrendingPreset{
+			RoPreset:  p,
+			Score:     v.Score,
+			TotalLike: v.TotalLike,
+		})
+	}
+
+	for _, v := range tagScores.Items {
+		res.Tags = append(res.Tags, TrendingTag{
+			Tag:       v.Key,
+			Score:     v.Score,
+			TotalLike: v.TotalLike,
+		})
+		res.UpdatedAt = v.UpdatedAt
+	}
+
+	return &res, nil
+}
@@ -0,0 +1,305 @@
+package service
+
+import (
+	"fmt"
+	"ro-backend/appError"
+	"ro-backend/repository"
+	"slices"
+	"strings"
+)
+
+const maxRefine = 20
+const minGradedRefine = 11
+const maxTraitStat = 100
+
+var presetGrades = []string{"", "d", "c", "b", "a"}
+
+type PresetValidator interface {
+	Validate(repository.PresetModel) ([]appError.FieldError, error)
+}
+
+func NewPresetValidator(itemRepo repository.ItemRepository) PresetValidator {
+	return presetValidator{
+		itemRepo: itemRepo,
+		rules: []presetRule{
+			levelRule,
+			baseStatRule,
+			traitStatRule,
+			refineRule,
+			itemPositionRule,
+			cardSlotRule,
+		},
+	}
+}
+
+type presetRuleContext struct {
+	class      RoClass
+	knownClass bool
+	hasCatalog bool
+	items      map[int]repository.Item
+}
+
+type presetRule func(m repository.PresetModel, c presetRuleContext) []appError.FieldError
+
+type presetValidator struct {
+	itemRepo repository.ItemRepository
+	rules    []presetRule
+}
+
+func (v presetValidator) Validate(m repository.PresetModel) ([]appError.FieldError, error) {
+	// class ids outside of roClassRanges are checked against the highest caps
+	class, knownClass := findRoClass(m.Class)
+	c := presetRuleContext{
+		class:      class,
+		knownClass: knownClass,
+		items:      map[int]repository.Item{},
+	}
+
+	totalItem, err := v.itemRepo.CountItems()
+	if err != nil {
+		return nil, err
+	}
+
+	// item rules are skipped until the item DB has been imported
+	c.hasCatalog = totalItem > 0
+	if c.hasCatalog {
+		items, err := v.itemRepo.FindItemsByIds(presetItemIds(m))
+		if err != nil {
+			return nil, err
+		}
+		for _, item := range items {
+			c.items[item.Id] = item
+		}
+	}
+
+	errs := []appError.FieldError{}
+	for _, rule := range v.rules {
+		errs = append(errs, rule(m, c)...)
+	}
+
+	return errs, nil
+}
+
+// validatePresetModel runs every rule and prefixes the failed fields, e.g. "model." or "bulkData[0].model.".
+func validatePresetModel(v PresetValidator, m repository.PresetModel, prefix string) ([]appError.FieldError, error) {
+	errs, err := v.Validate(m)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range errs {
+		errs[i].Field = prefix + errs[i].Field
+	}
+
+	return errs, nil
+}
+
+func presetItemIds(m repository.PresetModel) []int {
+	ids := []int{}
+	for _, e := range m.Equipments() {
+		if e.ItemId != 0 {
+			ids = append(ids, e.ItemId)
+		}
+		for _, v := range e.Cards {
+			if v.ItemId != 0 {
+				ids = append(ids, v.ItemId)
+			}
+		}
+		for _, v := range e.Enchants {
+			if v.ItemId != 0 {
+				ids = append(ids, v.ItemId)
+			}
+		}
+	}
+
+	return ids
+}
+
+func fieldErr(field, format string, a ...interface{}) appError.FieldError {
+	return appError.FieldError{
+		Field:   field,
+		Message: fmt.Sprintf(format, a...),
+	}
+}
+
+func levelRule(m repository.PresetModel, c presetRuleContext) []appError.FieldError {
+	errs := []appError.FieldError{}
+	if m.Class <= 0 {
+		errs = append(errs, fieldErr("class", "is required"))
+	}
+	if m.Level < 1 || m.Level > c.class.MaxLevel {
+		errs = append(errs, fieldErr("level", "must be between 1 and %v", c.class.MaxLevel))
+	}
+	if m.JobLevel < 1 || m.JobLevel > c.class.MaxJobLevel {
+		errs = append(errs, fieldErr("jobLevel", "must be between 1 and %v", c.class.MaxJobLevel))
+	}
+
+	return errs
+}
+
+func baseStatRule(m repository.PresetModel, c presetRuleContext) []appError.FieldError {
+	stats := []struct {
+		field string
+		value int
+		job   int
+	}{
+		{"str", m.Str, m.JobStr},
+		{"agi", m.Agi, m.JobAgi},
+		{"vit", m.Vit, m.JobVit},
+		{"int", m.Int, m.JobInt},
+		{"dex", m.Dex, m.JobDex},
+		{"luk", m.Luk, m.JobLuk},
+	}
+
+	errs := []appError.FieldError{}
+	for _, v := range stats {
+		if v.value < 1 || v.value > c.class.MaxStat {
+			errs = append(errs, fieldErr(v.field, "must be between 1 and %v", c.class.MaxStat))
+		}
+		if v.job < 0 {
+			errs = append(errs, fieldErr("job"+strings.ToUpper(v.field[:1])+v.field[1:], "must not be negative"))
+		}
+	}
+
+	return errs
+}
+
+func traitStatRule(m repository.PresetModel, c presetRuleContext) []appError.FieldError {
+	traits := []struct {
+		field string
+		value int
+		job   int
+	}{
+		{"pow", m.Pow, m.JobPow},
+		{"sta", m.Sta, m.JobSta},
+		{"wis", m.Wis, m.JobWis},
+		{"spl", m.Spl, m.JobSpl},
+		{"con", m.Con, m.JobCon},
+		{"crt", m.Crt, m.JobCrt},
+	}
+
+	onlyFourth := c.knownClass && c.class.Tier != ClassTierFourth
+
+	errs := []appError.FieldError{}
+	for _, v := range traits {
+		if v.value < 0 || v.value > maxTraitStat {
+			errs = append(errs, fieldErr(v.field, "must be between 0 and %v", maxTraitStat))
+		} else if onlyFourth && v.value > 0 {
+			errs = append(errs, fieldErr(v.field, "is only available for 4th classes"))
+		}
+
+		if v.job < 0 {
+			errs = append(errs, fieldErr("job"+strings.ToUpper(v.field[:1])+v.field[1:], "must not be negative"))
+		} else if onlyFourth && v.job > 0 {
+			errs = append(errs, fieldErr("job"+strings.ToUpper(v.field[:1])+v.field[1:], "is only available for 4th classes"))
+		}
+	}
+
+	return errs
+}
+
+func refineRule(m repository.PresetModel, c presetRuleContext) []appError.FieldError {
+	errs := []appError.FieldError{}
+	for _, e := range m.Equipments() {
+		grade := strings.ToLower(e.Grade)
+		if !slices.Contains(presetGrades, grade) {
+			errs = append(errs, fieldErr(e.Position+"Grade", "is not a valid grade"))
+		}
+
+		if !e.HasRefine {
+			continue
+		}
+
+		refineField := e.Position + "Refine"
+		if e.Refine < 0 || e.Refine > maxRefine {
+			errs = append(errs, fieldErr(refineField, "must be between 0 and %v", maxRefine))
+			continue
+		}
+		if grade != "" && e.Refine < minGradedRefine {
+			errs = append(errs, fieldErr(refineField, "must be at least %v for grade %v", minGradedRefine, strings.ToUpper(grade)))
+		}
+		if e.ItemId == 0 && e.Refine > 0 {
+			errs = append(errs, fieldErr(refineField, "has no equipment"))
+		}
+		if item, found := c.items[e.ItemId]; found && !item.Refinable && e.Refine > 0 {
+			errs = append(errs, fieldErr(refineField, "%v is not refinable", item.Name))
+		}
+	}
+
+	return errs
+}
+
+func itemPositionRule(m repository.PresetModel, c presetRuleContext) []appError.FieldError {
+	if !c.hasCatalog {
+		return nil
+	}
+
+	errs := []appError.FieldError{}
+	for _, e := range m.Equipments() {
+		if e.ItemId != 0 {
+			item, found := c.items[e.ItemId]
+			if !found {
+				errs = append(errs, fieldErr(e.Position, "item %v is unknown", e.ItemId))
+			} else if !item.CanBeUsedAt(e.Position) {
+				errs = append(errs, fieldErr(e.Position, "%v cannot be equipped at %v", item.Name, e.Position))
+			}
+		}
+
+		for _, v := range e.Cards {
+			if v.ItemId == 0 {
+				continue
+			}
+
+			card, found := c.items[v.ItemId]
+			if !found {
+				errs = append(errs, fieldErr(v.Field, "card %v is unknown", v.ItemId))
+			} else if card.Type != repository.ItemType.Card || !card.CanBeUsedAt(e.CardPosition) {
+				errs = append(errs, fieldErr(v.Field, "%v cannot be put into %v", card.Name, e.Position))
+			}
+		}
+
+		item := c.items[e.ItemId]
+		for _, v := range e.Enchants {
+			if v.ItemId == 0 {
+				continue
+			}
+
+			enchant, found := c.items[v.ItemId]
+			if !found {
+				errs = append(errs, fieldErr(v.Field, "enchant %v is unknown", v.ItemId))
+			} else if enchant.Type != repository.ItemType.Enchant && enchant.Type != repository.ItemType.Card {
+				errs = append(errs, fieldErr(v.Field, "%v is not an enchant", enchant.Name))
+			} else if len(item.AllowedEnchants) > 0 && !slices.Contains(item.AllowedEnchants, v.ItemId) {
+				errs = append(errs, fieldErr(v.Field, "%v cannot be enchanted on %v", enchant.Name, item.Name))
+			}
+		}
+	}
+
+	return errs
+}
+
+func cardSlotRule(m repository.PresetModel, c presetRuleContext) []appError.FieldError {
+	errs := []appError.FieldError{}
+	for _, e := range m.Equipments() {
+		totalCard := 0
+		for _, v := range e.Cards {
+			if v.ItemId != 0 {
+				totalCard++
+			}
+		}
+		if totalCard == 0 {
+			continue
+		}
+
+		if e.ItemId == 0 {
+			errs = append(errs, fieldErr(e.Cards[0].Field, "has no equipment"))
+			continue
+		}
+
+		if item, found := c.items[e.ItemId]; found && totalCard > item.CardSlots {
+			errs = append(errs, fieldErr(e.Position, "%v has only %v card slots", item.Name, item.CardSlots))
+		}
+	}
+
+	return errs
+}
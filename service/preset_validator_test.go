@@ -0,0 +1,88 @@
+package service
+
+import (
+	"ro-backend/repository"
+	"testing"
+)
+
+// emptyItemRepo is an item DB that was never imported, the item rules are skipped.
+type emptyItemRepo struct {
+	repository.ItemRepository
+}
+
+func (emptyItemRepo) CountItems() (int, error) {
+	return 0, nil
+}
+
+func validPresetModel(classId, level int) repository.PresetModel {
+	return repository.PresetModel{Class: classId, Level: level, JobLevel: 50, Str: 1, Agi: 1, Vit: 1, Int: 1, Dex: 1, Luk: 1}
+}
+
+func hasFieldError(t *testing.T, v PresetValidator, m repository.PresetModel, field string) bool {
+	errs, err := v.Validate(m)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, e := range errs {
+		if e.Field == field {
+			return true
+		}
+	}
+
+	return false
+}
+
+func TestValidateTraitStats(t *testing.T) {
+	v := NewPresetValidator(emptyItemRepo{})
+
+	tests := []struct {
+		name    string
+		classId int
+		level   int
+		pow     int
+		jobPow  int
+		field   string
+		want    bool
+	}{
+		{"first class with trait stats", 7, 99, 10, 0, "pow", true},
+		{"third class with job trait stats", 4060, 200, 0, 5, "jobPow", true},
+		{"fourth class with trait stats", 4252, 250, 10, 5, "pow", false},
+		{"unknown class with trait stats", 9999, 250, 10, 0, "pow", false},
+	}
+
+	for _, tt := range tests {
+		m := validPresetModel(tt.classId, tt.level)
+		m.Pow = tt.pow
+		m.JobPow = tt.jobPow
+		if got := hasFieldError(t, v, m, tt.field); got != tt.want {
+			t.Errorf("%v: %v rejected = %v, want %v", tt.name, tt.field, got, tt.want)
+		}
+	}
+}
+
+func TestValidateClassCaps(t *testing.T) {
+	v := NewPresetValidator(emptyItemRepo{})
+
+	tests := []struct {
+		name    string
+		classId int
+		level   int
+		str     int
+		field   string
+		want    bool
+	}{
+		{"normal class over level 99", 7, 100, 1, "level", true},
+		{"normal class over stat 99", 7, 99, 100, "str", true},
+		{"third class at stat 130", 4060, 200, 130, "str", false},
+		{"unknown class at level 275", 9999, 275, 1, "level", false},
+	}
+
+	for _, tt := range tests {
+		m := validPresetModel(tt.classId, tt.level)
+		m.Str = tt.str
+		if got := hasFieldError(t, v, m, tt.field); got != tt.want {
+			t.Errorf("%v: %v rejected = %v, want %v", tt.name, tt.field, got, tt.want)
+		}
+	}
+}
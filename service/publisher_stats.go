@@ -0,0 +1,45 @@
+package service
+
+// LeaderboardWindowAll counts everything since the start, the other windows are the trending ones.
+const LeaderboardWindowAll = "all"
+
+type PublisherTotals struct {
+	TotalLike      int
+	TotalPublished int
+	TotalFork      int
+}
+
+type PublisherStats struct {
+	UserId   string
+	UserName string
+	PublisherTotals
+	// ByClass is only filled for the stats of one publisher
+	ByClass map[int]PublisherTotals
+}
+
+// LeaderboardRequest ClassId 0 ranks every class, Sort is likes, forks or presets.
+type LeaderboardRequest struct {
+	ClassId int
+	Window  string
+	Sort    string
+	Skip    int
+	Take    int
+}
+
+type LeaderboardResult struct {
+	Window string
+	Items  []PublisherStats
+	Total  int
+}
+
+type PublisherStatsRequest struct {
+	UserId string
+	Window string
+}
+
+type PublisherStatsService interface {
+	// Leaderboard reads the ranking stored by RefreshLeaderboard
+	Leaderboard(LeaderboardRequest) (*LeaderboardResult, error)
+	RefreshLeaderboard() (int, error)
+	FindPublisherStats(PublisherStatsRequest) (*PublisherStats, error)
+}
@@ -0,0 +1,262 @@
+package service
+
+import (
+	"fmt"
+	"ro-backend/appError"
+	"ro-backend/repository"
+	"time"
+)
+
+func NewPublisherStatsService(presetRepo repository.RoPresetRepository, tagRepo repository.PresetTagRepository, userRepo repository.UserRepository, leaderboardRepo repository.PublisherLeaderboardRepository) PublisherStatsService {
+	return publisherStatsService{
+		presetRepo:      presetRepo,
+		tagRepo:         tagRepo,
+		userRepo:        userRepo,
+		leaderboardRepo: leaderboardRepo,
+	}
+}
+
+type publisherStatsService struct {
+	presetRepo      repository.RoPresetRepository
+	tagRepo         repository.PresetTagRepository
+	userRepo        repository.UserRepository
+	leaderboardRepo repository.PublisherLeaderboardRepository
+}
+
+var leaderboardWindows = []string{
+	LeaderboardWindowAll,
+	string(repository.TrendingWindows.Day),
+	string(repository.TrendingWindows.Week),
+	string(repository.TrendingWindows.Month),
+}
+
+// parseStatsWindow gives nil since for the whole history.
+func parseStatsWindow(window string) (string, *time.Time, error) {
+	if window == "" || window == LeaderboardWindowAll {
+		return LeaderboardWindowAll, nil, nil
+	}
+
+	w := repository.TrendingWindow(window)
+	if !w.IsValid() {
+		return "", nil, fmt.Errorf(appError.ErrBadInput)
+	}
+
+	since := time.Now().Add(-w.Duration())
+
+	return window, &since, nil
+}
+
+func parseLeaderboardSort(sortBy string) (repository.LeaderboardSort, error) {
+	switch repository.LeaderboardSort(sortBy) {
+	case "", repository.LeaderboardSorts.Likes:
+		return repository.LeaderboardSorts.Likes, nil
+	case repository.LeaderboardSorts.Forks, repository.LeaderboardSorts.Presets:
+		return repository.LeaderboardSort(sortBy), nil
+	}
+
+	return "", fmt.Errorf(appError.ErrBadInput)
+}
+
+type publisherClassTotals map[string]map[int]*PublisherTotals
+
+func (t publisherClassTotals) add(counts []repository.PublisherCount, field func(*PublisherTotals) *int) {
+	for _, v := range counts {
+		if t[v.UserId] == nil {
+			t[v.UserId] = map[int]*PublisherTotals{}
+		}
+		if t[v.UserId][v.ClassId] == nil {
+			t[v.UserId][v.ClassId] = &PublisherTotals{}
+		}
+		*field(t[v.UserId][v.ClassId]) += v.Total
+	}
+}
+
+// countByPublisher reads likes, published presets and forks of every publisher, or of one, per class.
+func (s publisherStatsService) countByPublisher(i repository.PublisherStatsInput) (publisherClassTotals, error) {
+	likes, err := s.tagRepo.CountLikesByPublisher(i)
+	if err != nil {
+		return nil, err
+	}
+
+	published, err := s.presetRepo.CountPublishedByUser(i)
+	if err != nil {
+		return nil, err
+	}
+
+	forks, err := s.presetRepo.CountForksBySourceUser(i)
+	if err != nil {
+		return nil, err
+	}
+
+	totals := publisherClassTotals{}
+	totals.add(likes, func(t *PublisherTotals) *int { return &t.TotalLike })
+	totals.add(published, func(t *PublisherTotals) *int { return &t.TotalPublished })
+	totals.add(forks, func(t *PublisherTotals) *int { return &t.TotalFork })
+
+	return totals, nil
+}
+
+func sumPublisherTotals(byClass map[int]*PublisherTotals, classId int) PublisherTotals {
+	var sum PublisherTotals
+	for c, v := range byClass {
+		if classId != 0 && c != classId {
+			continue
+		}
+		sum.TotalLike += v.TotalLike
+		sum.TotalPublished += v.TotalPublished
+		sum.TotalFork += v.TotalFork
+	}
+
+	return sum
+}
+
+func (s publisherStatsService) userNames(userIds []string) (map[string]string, error) {
+	names := map[string]string{}
+	if len(userIds) == 0 {
+		return names, nil
+	}
+
+	users, err := s.userRepo.FindUsersByIds(userIds)
+	if err != nil {
+		return nil, err
+	}
+	for _, v := range users {
+		names[v.Id] = v.Name
+	}
+
+	return names, nil
+}
+
+// RefreshLeaderboard recounts every publisher of every window, Leaderboard only reads the stored rows.
+func (s publisherStatsService) RefreshLeaderboard() (int, error) {
+	refreshedAt := time.Now()
+	total := 0
+	for _, window := range leaderboardWindows {
+		_, since, err := parseStatsWindow(window)
+		if err != nil {
+			return total, err
+		}
+
+		totals, err := s.countByPublisher(repository.PublisherStatsInput{Since: since})
+		if err != nil {
+			return total, err
+		}
+
+		rows := []repository.PublisherLeaderboardRow{}
+		row := func(userId string, classId int, t PublisherTotals) repository.PublisherLeaderboardRow {
+			return repository.PublisherLeaderboardRow{
+				Window:         window,
+				ClassId:        classId,
+				UserId:         userId,
+				TotalLike:      t.TotalLike,
+				TotalPublished: t.TotalPublished,
+				TotalFork:      t.TotalFork,
+				UpdatedAt:      refreshedAt,
+			}
+		}
+		for userId, byClass := range totals {
+			sum := sumPublisherTotals(byClass, 0)
+			if sum == (PublisherTotals{}) {
+				continue
+			}
+			rows = append(rows, row(userId, 0, sum))
+
+			// class 0 is the row of every class
+			for classId, v := range byClass {
+				if classId != 0 && *v != (PublisherTotals{}) {
+					rows = append(rows, row(userId, classId, *v))
+				}
+			}
+		}
+
+		err = s.leaderboardRepo.ReplaceLeaderboard(window, rows, refreshedAt)
+		if err != nil {
+			return total, err
+		}
+		total += len(rows)
+	}
+
+	return total, nil
+}
+
+func (s publisherStatsService) Leaderboard(r LeaderboardRequest) (*LeaderboardResult, error) {
+	window, _, err := parseStatsWindow(r.Window)
+	if err != nil {
+		return nil, err
+	}
+	sortBy, err := parseLeaderboardSort(r.Sort)
+	if err != nil {
+		return nil, err
+	}
+	if r.ClassId < 0 {
+		return nil, fmt.Errorf(appError.ErrBadInput)
+	}
+
+	rows, err := s.leaderboardRepo.FindLeaderboard(repository.FindLeaderboardInput{
+		Window:  window,
+		ClassId: r.ClassId,
+		Sort:    sortBy,
+		Skip:    r.Skip,
+		Take:    r.Take,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	res := LeaderboardResult{
+		Window: window,
+		Items:  []PublisherStats{},
+		Total:  rows.Total,
+	}
+
+	userIds := []string{}
+	for _, v := range rows.Items {
+		userIds = append(userIds, v.UserId)
+	}
+	names, err := s.userNames(userIds)
+	if err != nil {
+		return nil, err
+	}
+	for _, v := range rows.Items {
+		res.Items = append(res.Items, PublisherStats{
+			UserId:   v.UserId,
+			UserName: names[v.UserId],
+			PublisherTotals: PublisherTotals{
+				TotalLike:      v.TotalLike,
+				TotalPublished: v.TotalPublished,
+				TotalFork:      v.TotalFork,
+			},
+		})
+	}
+
+	return &res, nil
+}
+
+func (s publisherStatsService) FindPublisherStats(r PublisherStatsRequest) (*PublisherStats, error) {
+	_, since, err := parseStatsWindow(r.Window)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := s.userRepo.FindUserById(r.UserId)
+	if err != nil {
+		return nil, err
+	}
+
+	totals, err := s.countByPublisher(repository.PublisherStatsInput{Since: since, UserId: r.UserId})
+	if err != nil {
+		return nil, err
+	}
+
+	res := PublisherStats{
+		UserId:          r.UserId,
+		UserName:        user.Name,
+		PublisherTotals: sumPublisherTotals(totals[r.UserId], 0),
+		ByClass:         map[int]PublisherTotals{},
+	}
+	for classId, v := range totals[r.UserId] {
+		res.ByClass[classId] = *v
+	}
+
+	return &res, nil
+}
@@ -0,0 +1,51 @@
+package service
+
+type ClassTier int
+
+const (
+	ClassTierNormal ClassTier = iota
+	ClassTierThird
+	ClassTierFourth
+)
+
+type RoClass struct {
+	Tier        ClassTier
+	Reborn      bool
+	MaxLevel    int
+	MaxJobLevel int
+	MaxStat     int
+}
+
+// class ids follow the job ids of rAthena (enum e_job in src/common/mmo.hpp) and the caps its
+// renewal job database (db/re/job_stats.yml, job_exp.yml), ranges are inclusive.
+var roClassRanges = []struct {
+	From  int
+	To    int
+	Class RoClass
+}{
+	{1, 25, RoClass{Tier: ClassTierNormal, MaxLevel: 99, MaxJobLevel: 70, MaxStat: 99}},
+	{4046, 4049, RoClass{Tier: ClassTierNormal, MaxLevel: 99, MaxJobLevel: 50, MaxStat: 99}},
+	{4008, 4022, RoClass{Tier: ClassTierNormal, Reborn: true, MaxLevel: 99, MaxJobLevel: 70, MaxStat: 99}},
+	{4054, 4059, RoClass{Tier: ClassTierThird, MaxLevel: 200, MaxJobLevel: 70, MaxStat: 130}},
+	{4060, 4065, RoClass{Tier: ClassTierThird, Reborn: true, MaxLevel: 200, MaxJobLevel: 70, MaxStat: 130}},
+	{4066, 4072, RoClass{Tier: ClassTierThird, MaxLevel: 200, MaxJobLevel: 70, MaxStat: 130}},
+	{4073, 4079, RoClass{Tier: ClassTierThird, Reborn: true, MaxLevel: 200, MaxJobLevel: 70, MaxStat: 130}},
+	{4190, 4190, RoClass{Tier: ClassTierThird, MaxLevel: 200, MaxJobLevel: 70, MaxStat: 130}},
+	{4211, 4215, RoClass{Tier: ClassTierThird, MaxLevel: 200, MaxJobLevel: 70, MaxStat: 130}},
+	{4239, 4240, RoClass{Tier: ClassTierThird, MaxLevel: 200, MaxJobLevel: 70, MaxStat: 130}},
+	{4252, 4264, RoClass{Tier: ClassTierFourth, Reborn: true, MaxLevel: 275, MaxJobLevel: 55, MaxStat: 130}},
+	{4302, 4308, RoClass{Tier: ClassTierFourth, Reborn: true, MaxLevel: 275, MaxJobLevel: 55, MaxStat: 130}},
+}
+
+// unknown classes get the highest caps
+var anyRoClass = RoClass{Tier: ClassTierFourth, Reborn: true, MaxLevel: 275, MaxJobLevel: 70, MaxStat: 130}
+
+func findRoClass(classId int) (RoClass, bool) {
+	for _, v := range roClassRanges {
+		if classId >= v.From && classId <= v.To {
+			return v.Class, true
+		}
+	}
+
+	return anyRoClass, false
+}
@@ -1,25 +1,170 @@
 package service
 
-import "ro-backend/repository"
+import (
+	"ro-backend/repository"
+	"time"
+)
 
 type CheckPresetOwnerRequest struct {
 	Id     string `json:"id"`
 	UserId string `json:"userId"`
 }
 
+// FindMyPresetsRequest with FolderId "" finds presets outside of any folder, nil finds all.
+// Limit 0 finds every preset, Cursor is the NextCursor of the previous page.
+type FindMyPresetsRequest struct {
+	UserId       string
+	IncludeModel bool
+	FolderId     *string
+	ClassId      *int
+	IsPublished  *bool
+	Label        string
+	Sort         string
+	Cursor       string
+	Limit        int
+}
+
+// MyPresetPage NextCursor is empty on the last page.
+type MyPresetPage struct {
+	Items      []repository.RoPreset
+	NextCursor string
+}
+
 type FindPresetsByTagsRequest struct {
 	ClassId int
 	Skip    int
 	Take    int
 }
 
+type SearchPublishedPresetsRequest struct {
+	ClassId int
+	Skip    int
+	Take    int
+}
+
+type SearchPresetsByItemRequest struct {
+	ItemId  int
+	ClassId int
+	Slot    string
+	Skill   string
+	Sort    string
+	Skip    int
+	Take    int
+}
+
+// DeletePresetRequest Version is the version the client last saw.
+type DeletePresetRequest struct {
+	Id      string
+	UserId  string
+	Version *int
+}
+
+// SearchPresetsByItemOptionRequest MinValue nil finds any value.
+type SearchPresetsByItemOptionRequest struct {
+	Option   string
+	ClassId  int
+	Slot     string
+	Skill    string
+	MinValue *int
+	Sort     string
+	Skip     int
+	Take     int
+}
+
+type RestorePresetRequest struct {
+	Id     string
+	UserId string
+	Role   string
+}
+
+type PublishedVersionRequest struct {
+	PresetId string
+	UserId   string
+	Version  int
+}
+
+type ForkPresetRequest struct {
+	PresetId string
+	UserId   string
+	UserName string
+	Role     string
+	Label    string
+}
+
+type PresetQuota struct {
+	Used      int
+	Limit     int
+	Unlimited bool
+}
+
+type FindForkTreeRequest struct {
+	PresetId string
+	UserId   string
+}
+
+// PresetForkNode leaves out presets of other users that are not published, HiddenForkCount counts
+// the ones left out below it. A root that cannot be read is an empty node holding the tree.
+type PresetForkNode struct {
+	Id              string
+	PublishName     string
+	PublisherName   string
+	IsPublished     bool
+	ForkCount       int
+	CreatedAt       time.Time
+	Children        []*PresetForkNode
+	HiddenForkCount int
+}
+
+type DiffPresetsRequest struct {
+	A      string
+	B      string
+	UserId string
+}
+
+type PresetDiffSide struct {
+	Id          string `json:"id"`
+	Label       string `json:"label"`
+	PublishName string `json:"publishName"`
+	ClassId     int    `json:"classId"`
+}
+
+type PresetDiff struct {
+	A     PresetDiffSide   `json:"a"`
+	B     PresetDiffSide   `json:"b"`
+	Slots []PresetSlotDiff `json:"slots"`
+}
+
 type RoPresetService interface {
 	FindPresetById(CheckPresetOwnerRequest) (*repository.RoPreset, error)
 	FindPresetsByUserId(userId string, includeModel bool) ([]repository.RoPreset, error)
+	FindMyPresets(FindMyPresetsRequest) (*MyPresetPage, error)
 	CreatePreset(repository.CreatePresetInput) (*repository.RoPreset, error)
 	BulkCreatePresets(repository.BulkCreatePresetInput) ([]repository.RoPreset, error)
 	UpdatePreset(id string, i repository.UpdatePresetInput) (*repository.RoPreset, error)
 	PublishPreset(id string, i repository.UpdatePresetInput) (*repository.RoPreset, error)
 	UnPublishPreset(id string, i repository.UpdatePresetInput) (*repository.RoPreset, error)
-	DeletePresetById(CheckPresetOwnerRequest) (*int, error)
+	ForceUnpublishPreset(id string) error
+	ForceUnpublishUserPresets(userId string) (int, error)
+	RepublishPreset(id string, i repository.UpdatePresetInput) (*repository.RoPreset, error)
+	FindPublishedVersions(CheckPresetOwnerRequest) ([]repository.PresetPublishedVersion, error)
+	FindPublishedVersion(PublishedVersionRequest) (*repository.PresetPublishedVersion, error)
+	DeletePresetById(DeletePresetRequest) (*int, error)
+	FindTrash(userId string) ([]repository.RoPreset, error)
+	RestorePreset(RestorePresetRequest) (*repository.RoPreset, error)
+	PurgePreset(CheckPresetOwnerRequest) error
+	PurgeDeletedPresets(t time.Time) (int, error)
+	FindPublishedPresetById(id string) (*repository.RoPreset, error)
+	SearchPublishedPresets(SearchPublishedPresetsRequest) (*repository.PartialSearchRoPresetResult, error)
+	ForkPreset(ForkPresetRequest) (*repository.RoPreset, error)
+	FindForkTree(FindForkTreeRequest) (*PresetForkNode, error)
+	FindQuota(userId, role string) (*PresetQuota, error)
+	CalcStatBudget(repository.PresetModel) StatBudget
+	DiffPresets(DiffPresetsRequest) (*PresetDiff, error)
+	SearchPresetsByItem(SearchPresetsByItemRequest) (*repository.PartialSearchRoPresetResult, error)
+	SearchPresetsByItemOption(SearchPresetsByItemOptionRequest) (*repository.PartialSearchRoPresetResult, error)
+	RebuildDerivedFields() (int, error)
+	MigrateSchemaVersion() (int, error)
+	BackfillPublishedSnapshots() (int, error)
+	// SampleItemOptionTexts shows what the calculator really stores, to check the option grammar against.
+	SampleItemOptionTexts(take int) ([]repository.ItemOptionTextSample, error)
 }
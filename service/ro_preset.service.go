@@ -3,17 +3,47 @@ package service
 import (
 	"fmt"
 	"ro-backend/appError"
+	"ro-backend/configuration"
 	"ro-backend/repository"
 	"time"
+
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/mongo"
 )
 
-func NewRoPresetService(repo repository.RoPresetRepository, tagRepo repository.PresetTagRepository) RoPresetService {
-	return roPresetService{presetRepo: repo, tagRepo: tagRepo}
+type RoPresetServiceParam struct {
+	PresetRepo   repository.RoPresetRepository
+	TagRepo      repository.PresetTagRepository
+	RevisionRepo repository.PresetRevisionRepository
+	QuotaRepo    repository.PresetQuotaRepository
+	VersionRepo  repository.PresetPublishedVersionRepository
+	UserRepo     repository.UserRepository
+	FolderRepo   repository.PresetFolderRepository
+	Validator    PresetValidator
+}
+
+func NewRoPresetService(p RoPresetServiceParam) RoPresetService {
+	return roPresetService{
+		presetRepo:   p.PresetRepo,
+		tagRepo:      p.TagRepo,
+		revisionRepo: p.RevisionRepo,
+		quotaRepo:    p.QuotaRepo,
+		versionRepo:  p.VersionRepo,
+		userRepo:     p.UserRepo,
+		folderRepo:   p.FolderRepo,
+		validator:    p.Validator,
+	}
 }
 
 type roPresetService struct {
-	presetRepo repository.RoPresetRepository
-	tagRepo    repository.PresetTagRepository
+	presetRepo   repository.RoPresetRepository
+	tagRepo      repository.PresetTagRepository
+	revisionRepo repository.PresetRevisionRepository
+	quotaRepo    repository.PresetQuotaRepository
+	versionRepo  repository.PresetPublishedVersionRepository
+	userRepo     repository.UserRepository
+	folderRepo   repository.PresetFolderRepository
+	validator    PresetValidator
 }
 
 func (s roPresetService) ValidatePresetOwner(r CheckPresetOwnerRequest) (*repository.RoPreset, error) {
@@ -38,14 +68,68 @@ func (s roPresetService) UpdatePreset(id string, i repository.UpdatePresetInput)
 		return nil, err
 	}
 
-	if p.IsPublished {
-		return nil, fmt.Errorf(appError.ErrCannotUpdatePublishedPreset)
+	err = checkPresetVersion(p, i.Version)
+	if err != nil {
+		return nil, err
 	}
 
-	err = s.presetRepo.UpdatePreset(id, repository.UpdatePresetInput{
-		Label: i.Label,
-		Model: i.Model,
-	})
+	// presets published before drafts existed freeze their model first, or the edit would go public
+	if p.IsPublished && p.Published == nil {
+		err = s.presetRepo.BackfillPublishedSnapshot(id)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// published presets only change the draft, the published copy is replaced by RepublishPreset
+	var statOverspent *bool
+	if i.Model != nil {
+		errs, err := validatePresetModel(s.validator, *i.Model, "model.")
+		if err != nil {
+			return nil, err
+		}
+
+		budget := CalcStatBudget(*i.Model)
+		errs = append(errs, statBudgetErrors(budget, "model.")...)
+		if len(errs) > 0 {
+			return nil, &appError.ValidationError{Errors: errs}
+		}
+		statOverspent = &budget.Overspent
+
+		err = checkFolderClass(s.folderRepo, *p, i.Model.Class)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	update := func() error {
+		return s.presetRepo.UpdatePreset(id, repository.UpdatePresetInput{
+			Label:         i.Label,
+			Model:         i.Model,
+			StatOverspent: statOverspent,
+			Version:       i.Version,
+		})
+	}
+	if i.Model != nil || i.Label != "" {
+		// a label only update keeps the model, the revision still records the new label
+		model := i.Model
+		if model == nil {
+			current, err := s.presetRepo.FindPresetById(repository.FindPresetByIdInput{
+				Id:           id,
+				InCludeModel: true,
+			})
+			if err != nil {
+				return nil, err
+			}
+			model = &current.Model
+		}
+		if i.Label != "" {
+			p.Label = i.Label
+		}
+		err = recordPresetRevision(s.presetRepo, s.revisionRepo, *p, *model, i.UserId, update)
+	} else {
+		err = update()
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -62,14 +146,25 @@ func (s roPresetService) PublishPreset(id string, i repository.UpdatePresetInput
 		return nil, err
 	}
 
+	err = checkPresetVersion(p, i.Version)
+	if err != nil {
+		return nil, err
+	}
+
 	if p.IsPublished {
-		return nil, fmt.Errorf(appError.ErrCannotUpdatePublishedPreset)
+		return nil, fmt.Errorf(appError.ErrPresetAlreadyPublished)
 	}
 
-	err = s.presetRepo.UpdatePreset(id, repository.UpdatePresetInput{
+	err = checkUserActive(s.userRepo, i.UserId)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.publishDraft(id, repository.UpdatePresetInput{
 		PublishName: i.PublishName,
 		IsPublished: true,
 		PublishedAt: time.Now(),
+		Version:     i.Version,
 	})
 	if err != nil {
 		return nil, err
@@ -77,7 +172,7 @@ func (s roPresetService) PublishPreset(id string, i repository.UpdatePresetInput
 
 	s.tagRepo.CreateTags(repository.CreateTagInput{
 		PublisherId: i.UserId,
-		Tags:        []string{"no_tag"},
+		Tags:        []string{repository.NoTag},
 		ClassId:     p.ClassId,
 		PresetId:    p.Id,
 	})
@@ -88,22 +183,133 @@ func (s roPresetService) PublishPreset(id string, i repository.UpdatePresetInput
 	})
 }
 
-func (s roPresetService) UnPublishPreset(id string, i repository.UpdatePresetInput) (*repository.RoPreset, error) {
+// RepublishPreset replaces the published copy with the draft, tags and likes are kept.
+func (s roPresetService) RepublishPreset(id string, i repository.UpdatePresetInput) (*repository.RoPreset, error) {
 	p, err := s.ValidatePresetOwner(CheckPresetOwnerRequest{Id: id, UserId: i.UserId})
 	if err != nil {
 		return nil, err
 	}
 
+	err = checkPresetVersion(p, i.Version)
+	if err != nil {
+		return nil, err
+	}
+
 	if !p.IsPublished {
-		return p, nil
+		return nil, fmt.Errorf(appError.ErrPresetNotPublished)
+	}
+
+	err = checkUserActive(s.userRepo, i.UserId)
+	if err != nil {
+		return nil, err
+	}
+
+	publishName := i.PublishName
+	if publishName == "" {
+		publishName = p.PublishName
+	}
+
+	err = s.publishDraft(id, repository.UpdatePresetInput{
+		PublishName: publishName,
+		Version:     i.Version,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := s.presetRepo.FindPresetById(repository.FindPresetByIdInput{
+		Id:           id,
+		InCludeModel: false,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// tags are searched by the class of the published copy, the draft class was already saved on p
+	if res.Published != nil && (p.Published == nil || res.Published.ClassId != p.Published.ClassId) {
+		err = s.tagRepo.UpdateTagsClassId(id, res.Published.ClassId)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return res, nil
+}
+
+// publishDraft freezes the current model as the next published version.
+func (s roPresetService) publishDraft(id string, i repository.UpdatePresetInput) error {
+	// presets saved before the flag existed are checked from the model
+	draft, err := s.presetRepo.FindPresetById(repository.FindPresetByIdInput{
+		Id:           id,
+		InCludeModel: true,
+	})
+	if err != nil {
+		return err
+	}
+	// publish rejects overspent models only when saves do too
+	errs := statBudgetErrors(CalcStatBudget(draft.Model), "model.")
+	if len(errs) > 0 {
+		return &appError.ValidationError{Errors: errs}
+	}
+
+	latest, err := s.versionRepo.FindLatestVersion(id)
+	if err != nil {
+		return err
+	}
+	if draft.Published != nil && draft.Published.Version > latest {
+		latest = draft.Published.Version
+	}
+
+	published := repository.NewPublishedPreset(latest+1, draft.Model)
+	i.Published = &published
+	err = s.presetRepo.UpdatePreset(id, i)
+	if err != nil {
+		return err
+	}
+
+	return s.versionRepo.CreateVersion(repository.PresetPublishedVersion{
+		Id:          uuid.NewString(),
+		PresetId:    id,
+		Version:     published.Version,
+		PublishName: i.PublishName,
+		ClassId:     published.ClassId,
+		Model:       published.Model,
+		PublishedAt: published.PublishedAt,
+	})
+}
+
+func (s roPresetService) FindPublishedVersions(r CheckPresetOwnerRequest) ([]repository.PresetPublishedVersion, error) {
+	_, err := s.ValidatePresetOwner(r)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.versionRepo.FindVersionsByPresetId(r.Id)
+}
+
+func (s roPresetService) FindPublishedVersion(r PublishedVersionRequest) (*repository.PresetPublishedVersion, error) {
+	_, err := s.ValidatePresetOwner(CheckPresetOwnerRequest{Id: r.PresetId, UserId: r.UserId})
+	if err != nil {
+		return nil, err
 	}
 
-	err = s.tagRepo.DeleteTagsByPresetId(p.Id)
+	return s.versionRepo.FindVersion(repository.FindPresetPublishedVersionInput{
+		PresetId: r.PresetId,
+		Version:  r.Version,
+	})
+}
+
+func (s roPresetService) UnPublishPreset(id string, i repository.UpdatePresetInput) (*repository.RoPreset, error) {
+	p, err := s.ValidatePresetOwner(CheckPresetOwnerRequest{Id: id, UserId: i.UserId})
 	if err != nil {
 		return nil, err
 	}
 
-	err = s.presetRepo.UnpublishedPreset(id)
+	if !p.IsPublished {
+		return p, nil
+	}
+
+	err = s.unpublishPreset(p.Id)
 	if err != nil {
 		return nil, err
 	}
@@ -114,19 +320,196 @@ func (s roPresetService) UnPublishPreset(id string, i repository.UpdatePresetInp
 	})
 }
 
-func (s roPresetService) DeletePresetById(r CheckPresetOwnerRequest) (*int, error) {
-	_, err := s.ValidatePresetOwner(CheckPresetOwnerRequest{Id: r.Id, UserId: r.UserId})
+func (s roPresetService) unpublishPreset(id string) error {
+	err := s.tagRepo.DeleteTagsByPresetId(id)
+	if err != nil {
+		return err
+	}
+
+	return s.presetRepo.UnpublishedPreset(id)
+}
+
+// ForceUnpublishPreset is UnPublishPreset for moderators, it skips the owner check.
+func (s roPresetService) ForceUnpublishPreset(id string) error {
+	p, err := s.presetRepo.FindPresetById(repository.FindPresetByIdInput{
+		Id:           id,
+		InCludeModel: false,
+	})
+	if err != nil {
+		return err
+	}
+	if !p.IsPublished {
+		return nil
+	}
+
+	return s.unpublishPreset(p.Id)
+}
+
+// ForceUnpublishUserPresets takes down every published preset of a banned user, the trash included.
+func (s roPresetService) ForceUnpublishUserPresets(userId string) (int, error) {
+	ids, err := s.presetRepo.FindPublishedPresetIdsByUserId(userId)
+	if err != nil {
+		return 0, err
+	}
+
+	for i, id := range ids {
+		err = s.unpublishPreset(id)
+		if err != nil {
+			return i, err
+		}
+	}
+
+	return len(ids), nil
+}
+
+// DeletePresetById moves the preset to the trash, its tags are hidden with their likes until a restore.
+func (s roPresetService) DeletePresetById(r DeletePresetRequest) (*int, error) {
+	p, err := s.ValidatePresetOwner(CheckPresetOwnerRequest{Id: r.Id, UserId: r.UserId})
+	if err != nil {
+		return nil, err
+	}
+
+	err = checkPresetVersion(p, r.Version)
+	if err != nil {
+		return nil, err
+	}
+
+	deleted, err := s.presetRepo.SoftDeletePresetById(r.Id, r.Version)
+	if err != nil {
+		return nil, err
+	}
+
+	if deleted > 0 {
+		// the preset comes back out of the trash when its tags or quota could not follow
+		now := time.Now()
+		err = s.tagRepo.SetTagsDeletedAt(r.Id, &now)
+		if err != nil {
+			s.presetRepo.RestorePresetById(r.Id)
+			return nil, err
+		}
+
+		err = s.quotaRepo.ReleaseQuota(r.UserId, deleted)
+		if err != nil {
+			s.tagRepo.SetTagsDeletedAt(r.Id, nil)
+			s.presetRepo.RestorePresetById(r.Id)
+			return nil, err
+		}
+	}
+
+	return &deleted, nil
+}
+
+func (s roPresetService) FindTrash(userId string) ([]repository.RoPreset, error) {
+	return s.presetRepo.FindDeletedPresetsByUserId(userId)
+}
+
+// RestorePreset takes the preset out of the trash, it counts against the quota again.
+func (s roPresetService) RestorePreset(r RestorePresetRequest) (*repository.RoPreset, error) {
+	p, err := s.presetRepo.FindDeletedPresetById(r.Id)
+	if err != nil {
+		return nil, err
+	}
+	if p.UserId != r.UserId {
+		return nil, fmt.Errorf(appError.ErrNotMyPreset)
+	}
+
+	err = s.reserveQuota(r.UserId, r.Role, 1)
+	if err != nil {
+		return nil, err
+	}
+
+	restored, err := s.presetRepo.RestorePresetById(r.Id)
+	if err != nil || restored == 0 {
+		s.quotaRepo.ReleaseQuota(r.UserId, 1)
+		if err == nil {
+			err = mongo.ErrNoDocuments
+		}
+		return nil, err
+	}
+
+	err = s.tagRepo.SetTagsDeletedAt(r.Id, nil)
 	if err != nil {
 		return nil, err
 	}
 
-	s.tagRepo.DeleteTagsByPresetId(r.Id)
+	return s.presetRepo.FindPresetById(repository.FindPresetByIdInput{
+		Id:           r.Id,
+		InCludeModel: false,
+	})
+}
+
+// PurgePreset deletes a preset in the trash for good.
+func (s roPresetService) PurgePreset(r CheckPresetOwnerRequest) error {
+	p, err := s.presetRepo.FindDeletedPresetById(r.Id)
+	if err != nil {
+		return err
+	}
+	if p.UserId != r.UserId {
+		return fmt.Errorf(appError.ErrNotMyPreset)
+	}
 
-	return s.presetRepo.DeletePresetById(r.Id)
+	return s.purgePreset(r.Id)
+}
+
+// PurgeDeletedPresets deletes every preset that went to the trash before t.
+func (s roPresetService) PurgeDeletedPresets(t time.Time) (int, error) {
+	ids, err := s.presetRepo.FindPresetIdsDeletedBefore(t)
+	if err != nil {
+		return 0, err
+	}
+
+	total := 0
+	for _, id := range ids {
+		err = s.purgePreset(id)
+		if err != nil {
+			return total, err
+		}
+		total++
+	}
+
+	return total, nil
+}
+
+func (s roPresetService) purgePreset(id string) error {
+	s.tagRepo.DeleteTagsByPresetId(id)
+	s.revisionRepo.DeleteRevisionsByPresetId(id)
+	s.versionRepo.DeleteVersionsByPresetId(id)
+
+	_, err := s.presetRepo.DeletePresetById(id)
+
+	return err
 }
 
 func (s roPresetService) BulkCreatePresets(r repository.BulkCreatePresetInput) ([]repository.RoPreset, error) {
-	return s.presetRepo.CreatePresets(r)
+	allErrs := []appError.FieldError{}
+	for i, v := range r.BulkData {
+		prefix := fmt.Sprintf("bulkData[%v].model.", i)
+		errs, err := validatePresetModel(s.validator, v.Model, prefix)
+		if err != nil {
+			return nil, err
+		}
+
+		budget := CalcStatBudget(v.Model)
+		allErrs = append(allErrs, errs...)
+		allErrs = append(allErrs, statBudgetErrors(budget, prefix)...)
+		r.BulkData[i].StatOverspent = budget.Overspent
+	}
+	if len(allErrs) > 0 {
+		return nil, &appError.ValidationError{Errors: allErrs}
+	}
+
+	err := s.reserveQuota(r.UserId, r.Role, len(r.BulkData))
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := s.presetRepo.CreatePresets(r)
+	if err != nil {
+		s.quotaRepo.ReleaseQuota(r.UserId, len(r.BulkData))
+		return nil, err
+	}
+
+	return res, nil
 }
 
 func (s roPresetService) FindPresetsByUserId(userId string, includeModel bool) ([]repository.RoPreset, error) {
@@ -141,12 +524,178 @@ func (s roPresetService) FindPresetsByUserId(userId string, includeModel bool) (
 	return res.Items, nil
 }
 
+func (s roPresetService) FindMyPresets(r FindMyPresetsRequest) (*MyPresetPage, error) {
+	sort := repository.PresetListSort(r.Sort)
+	if sort == "" {
+		sort = repository.PresetListSorts.UpdatedDesc
+	}
+	if !sort.IsValid() {
+		return nil, fmt.Errorf(appError.ErrBadInput)
+	}
+
+	after, err := decodePresetCursor(r.Cursor, sort)
+	if err != nil {
+		return nil, err
+	}
+
+	// one more than the limit tells if there is a next page
+	limit := r.Limit
+	if limit > 0 {
+		limit++
+	}
+
+	items, err := s.presetRepo.CursorSearchPresets(repository.CursorSearchRoPresetInput{
+		Filter: repository.PartialSearchRoPresetInput{
+			UserId:        &r.UserId,
+			FolderId:      r.FolderId,
+			ClassId:       r.ClassId,
+			IsPublished:   r.IsPublished,
+			LabelContains: &r.Label,
+			InCludeModel:  r.IncludeModel,
+		},
+		Sort:  sort,
+		After: after,
+		Limit: limit,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	page := &MyPresetPage{Items: items}
+	if r.Limit > 0 && len(items) > r.Limit {
+		page.Items = items[:r.Limit]
+		page.NextCursor = encodePresetCursor(sort, page.Items[r.Limit-1])
+	}
+
+	return page, nil
+}
+
 func (s roPresetService) CreatePreset(r repository.CreatePresetInput) (*repository.RoPreset, error) {
+	errs, err := validatePresetModel(s.validator, r.Model, "model.")
+	if err != nil {
+		return nil, err
+	}
+
+	errs = append(errs, statBudgetErrors(CalcStatBudget(r.Model), "model.")...)
+	if len(errs) > 0 {
+		return nil, &appError.ValidationError{Errors: errs}
+	}
+
+	return s.createPreset(r)
+}
+
+// createPreset skips the model rules, forks copy a preset that was already checked when it was saved.
+func (s roPresetService) createPreset(r repository.CreatePresetInput) (*repository.RoPreset, error) {
+	r.StatOverspent = CalcStatBudget(r.Model).Overspent
+
+	err := s.reserveQuota(r.UserId, r.Role, 1)
+	if err != nil {
+		return nil, err
+	}
+
 	res, err := s.presetRepo.CreatePreset(r)
+	if err != nil {
+		s.quotaRepo.ReleaseQuota(r.UserId, 1)
+		return nil, err
+	}
 
 	return (*repository.RoPreset)(res), err
 }
 
+func (s roPresetService) CalcStatBudget(m repository.PresetModel) StatBudget {
+	return CalcStatBudget(m)
+}
+
+func (s roPresetService) FindQuota(userId, role string) (*PresetQuota, error) {
+	used, err := s.initQuota(userId)
+	if err != nil {
+		return nil, err
+	}
+
+	limit := presetLimitByRole(role)
+
+	return &PresetQuota{
+		Used:      used,
+		Limit:     limit,
+		Unlimited: limit <= 0,
+	}, nil
+}
+
+// initQuota creates the quota document from the presets the user already has.
+func (s roPresetService) initQuota(userId string) (int, error) {
+	q, err := s.quotaRepo.FindQuota(userId)
+	if err == nil {
+		return q.Used, nil
+	}
+	if err != mongo.ErrNoDocuments {
+		return 0, err
+	}
+
+	total, err := s.presetRepo.CountPresetsByUserId(userId)
+	if err != nil {
+		return 0, err
+	}
+
+	err = s.quotaRepo.InitQuota(userId, total)
+	if err != nil {
+		return 0, err
+	}
+
+	q, err = s.quotaRepo.FindQuota(userId)
+	if err != nil {
+		return 0, err
+	}
+
+	return q.Used, nil
+}
+
+func (s roPresetService) reserveQuota(userId, role string, n int) error {
+	_, err := s.initQuota(userId)
+	if err != nil {
+		return err
+	}
+
+	ok, err := s.quotaRepo.ReserveQuota(userId, n, presetLimitByRole(role))
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return fmt.Errorf(appError.ErrPresetQuotaExceeded)
+	}
+
+	return nil
+}
+
+// checkPresetVersion fails before any work is done, the repository checks the version again when it writes.
+func checkPresetVersion(p *repository.RoPreset, version *int) error {
+	if version == nil {
+		return fmt.Errorf(appError.ErrPresetVersionRequired)
+	}
+	if *version != p.Version {
+		return &appError.VersionConflictError{CurrentVersion: p.Version}
+	}
+
+	return nil
+}
+
+// TrashRetention is how long a deleted preset stays in the trash before it is purged.
+func TrashRetention() time.Duration {
+	days := configuration.Config.Ro.TrashRetentionDays
+	if days <= 0 {
+		days = 30
+	}
+
+	return time.Duration(days) * 24 * time.Hour
+}
+
+func presetLimitByRole(role string) int {
+	if role == repository.UserRole.Admin {
+		return configuration.Config.Ro.AdminPresetLimit
+	}
+
+	return configuration.Config.Ro.PresetLimit
+}
+
 func (s roPresetService) FindPresetById(r CheckPresetOwnerRequest) (*repository.RoPreset, error) {
 	_, err := s.ValidatePresetOwner(CheckPresetOwnerRequest{Id: r.Id, UserId: r.UserId})
 	if err != nil {
@@ -160,3 +709,329 @@ func (s roPresetService) FindPresetById(r CheckPresetOwnerRequest) (*repository.
 
 	return (*repository.RoPreset)(res), err
 }
+
+func (s roPresetService) FindPublishedPresetById(id string) (*repository.RoPreset, error) {
+	res, err := s.presetRepo.FindPresetById(repository.FindPresetByIdInput{
+		Id:           id,
+		InCludeModel: true,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if !res.IsPublished {
+		return nil, mongo.ErrNoDocuments
+	}
+	res.UsePublishedSnapshot()
+
+	return res, nil
+}
+
+func (s roPresetService) SearchPublishedPresets(r SearchPublishedPresetsRequest) (*repository.PartialSearchRoPresetResult, error) {
+	isPublished := true
+	i := repository.PartialSearchRoPresetInput{
+		IsPublished:     &isPublished,
+		SortByPublished: true,
+		Skip:            &r.Skip,
+		Take:            &r.Take,
+		InCludeModel:    false,
+	}
+	if r.ClassId != 0 {
+		i.PublishedClassId = &r.ClassId
+	}
+
+	return usePublishedSnapshots(s.presetRepo.PartialSearchPresets(i))
+}
+
+// usePublishedSnapshots shows every result as it was published, never the draft.
+func usePublishedSnapshots(res *repository.PartialSearchRoPresetResult, err error) (*repository.PartialSearchRoPresetResult, error) {
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range res.Items {
+		res.Items[i].UsePublishedSnapshot()
+	}
+
+	return res, nil
+}
+
+func (s roPresetService) SearchPresetsByItem(r SearchPresetsByItemRequest) (*repository.PartialSearchRoPresetResult, error) {
+	sort, err := parseItemSearchSort(r.Sort)
+	if err != nil {
+		return nil, err
+	}
+
+	i := repository.SearchPresetsByItemInput{
+		ItemId: r.ItemId,
+		Sort:   sort,
+		Skip:   r.Skip,
+		Take:   r.Take,
+	}
+
+	if r.ClassId > 0 {
+		i.ClassId = &r.ClassId
+	}
+	if r.Slot != "" {
+		if !repository.IsPresetSlot(r.Slot) {
+			return nil, fmt.Errorf(appError.ErrBadInput)
+		}
+		i.Slot = &r.Slot
+	}
+	if r.Skill != "" {
+		i.Skill = &r.Skill
+	}
+
+	return usePublishedSnapshots(s.presetRepo.SearchPublishedPresetsByItem(i))
+}
+
+func (s roPresetService) SearchPresetsByItemOption(r SearchPresetsByItemOptionRequest) (*repository.PartialSearchRoPresetResult, error) {
+	sort, err := parseItemSearchSort(r.Sort)
+	if err != nil {
+		return nil, err
+	}
+
+	if r.Option == "" {
+		return nil, fmt.Errorf(appError.ErrBadInput)
+	}
+
+	i := repository.SearchPresetsByItemOptionInput{
+		Option:   r.Option,
+		MinValue: r.MinValue,
+		Sort:     sort,
+		Skip:     r.Skip,
+		Take:     r.Take,
+	}
+	if r.ClassId > 0 {
+		i.ClassId = &r.ClassId
+	}
+	if r.Slot != "" {
+		if !repository.IsPresetSlot(r.Slot) {
+			return nil, fmt.Errorf(appError.ErrBadInput)
+		}
+		i.Slot = &r.Slot
+	}
+	if r.Skill != "" {
+		i.Skill = &r.Skill
+	}
+
+	return usePublishedSnapshots(s.presetRepo.SearchPublishedPresetsByItemOption(i))
+}
+
+func parseItemSearchSort(sort string) (repository.PresetItemSearchSort, error) {
+	switch repository.PresetItemSearchSort(sort) {
+	case "", repository.PresetItemSearchSorts.Likes:
+		return repository.PresetItemSearchSorts.Likes, nil
+	case repository.PresetItemSearchSorts.Latest:
+		return repository.PresetItemSearchSorts.Latest, nil
+	}
+
+	return "", fmt.Errorf(appError.ErrBadInput)
+}
+
+func (s roPresetService) RebuildDerivedFields() (int, error) {
+	return s.presetRepo.RebuildDerivedFields()
+}
+
+func (s roPresetService) MigrateSchemaVersion() (int, error) {
+	return s.presetRepo.MigrateSchemaVersion()
+}
+
+func (s roPresetService) BackfillPublishedSnapshots() (int, error) {
+	return s.presetRepo.BackfillPublishedSnapshots()
+}
+
+func (s roPresetService) SampleItemOptionTexts(take int) ([]repository.ItemOptionTextSample, error) {
+	samples, err := s.presetRepo.SampleItemOptionTexts(take)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range samples {
+		_, samples[i].Parsed = repository.ParseItemOption(samples[i].Text)
+	}
+
+	return samples, nil
+}
+
+// findViewablePreset returns my own presets and published ones, others are not found.
+func (s roPresetService) findViewablePreset(id, userId string) (*repository.RoPreset, error) {
+	res, err := s.presetRepo.FindPresetById(repository.FindPresetByIdInput{
+		Id:           id,
+		InCludeModel: true,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if res.UserId == userId {
+		return res, nil
+	}
+
+	if !res.IsPublished {
+		return nil, mongo.ErrNoDocuments
+	}
+	res.UsePublishedSnapshot()
+
+	return res, nil
+}
+
+func (s roPresetService) DiffPresets(r DiffPresetsRequest) (*PresetDiff, error) {
+	a, err := s.findViewablePreset(r.A, r.UserId)
+	if err != nil {
+		return nil, err
+	}
+
+	b, err := s.findViewablePreset(r.B, r.UserId)
+	if err != nil {
+		return nil, err
+	}
+
+	return &PresetDiff{
+		A:     toPresetDiffSide(*a, r.UserId),
+		B:     toPresetDiffSide(*b, r.UserId),
+		Slots: GroupPresetModelDiff(DiffPresetModel(a.Model, b.Model)),
+	}, nil
+}
+
+// toPresetDiffSide only shows the private label to the owner.
+func toPresetDiffSide(p repository.RoPreset, viewerId string) PresetDiffSide {
+	side := PresetDiffSide{
+		Id:          p.Id,
+		PublishName: p.PublishName,
+		ClassId:     p.ClassId,
+	}
+	if p.UserId == viewerId {
+		side.Label = p.Label
+	}
+
+	return side
+}
+
+func (s roPresetService) ForkPreset(r ForkPresetRequest) (*repository.RoPreset, error) {
+	source, err := s.FindPublishedPresetById(r.PresetId)
+	if err != nil {
+		return nil, err
+	}
+
+	rootId := source.RootPresetId
+	if rootId == "" {
+		rootId = source.Id
+	}
+
+	label := r.Label
+	if label == "" {
+		label = source.PublishName
+	}
+
+	res, err := s.createPreset(repository.CreatePresetInput{
+		UserId:           r.UserId,
+		UserName:         r.UserName,
+		Role:             r.Role,
+		Label:            label,
+		Model:            source.Model,
+		ForkedFrom:       source.Id,
+		ForkedFromUserId: source.UserId,
+		RootPresetId:     rootId,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.presetRepo.IncreaseForkCount(source.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+func (s roPresetService) FindForkTree(r FindForkTreeRequest) (*PresetForkNode, error) {
+	p, err := s.presetRepo.FindPresetById(repository.FindPresetByIdInput{
+		Id:           r.PresetId,
+		InCludeModel: false,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if !p.IsPublished && p.UserId != r.UserId {
+		return nil, fmt.Errorf(appError.ErrNotMyPreset)
+	}
+
+	rootId := p.RootPresetId
+	if rootId == "" {
+		rootId = p.Id
+	}
+
+	root, err := s.presetRepo.FindPresetById(repository.FindPresetByIdInput{
+		Id:           rootId,
+		InCludeModel: false,
+	})
+	if err == mongo.ErrNoDocuments {
+		// the root was deleted, the requested preset becomes the top of its own tree
+		root = p
+	} else if err != nil {
+		return nil, err
+	}
+
+	forks, err := s.presetRepo.FindForksByRootId(rootId)
+	if err != nil {
+		return nil, err
+	}
+
+	nodes := map[string]*PresetForkNode{}
+	readable := map[string]bool{}
+	nodes[root.Id] = toPresetForkNode(*root)
+	readable[root.Id] = root.IsPublished || root.UserId == r.UserId
+	for _, v := range forks {
+		nodes[v.Id] = toPresetForkNode(v)
+		readable[v.Id] = v.IsPublished || v.UserId == r.UserId
+	}
+
+	for _, v := range forks {
+		if parent, found := nodes[v.ForkedFrom]; found && v.Id != root.Id {
+			parent.Children = append(parent.Children, nodes[v.Id])
+		}
+	}
+
+	top := nodes[root.Id]
+	collapseHiddenForks(top, readable)
+	if !readable[root.Id] {
+		// the tree keeps its top, only its place is shown
+		*top = PresetForkNode{
+			Children:        top.Children,
+			HiddenForkCount: top.HiddenForkCount,
+		}
+	}
+
+	return top, nil
+}
+
+// collapseHiddenForks leaves out the forks of n the viewer cannot read, their forks move up to n
+// and they are only counted in HiddenForkCount.
+func collapseHiddenForks(n *PresetForkNode, readable map[string]bool) {
+	children := []*PresetForkNode{}
+	for _, child := range n.Children {
+		collapseHiddenForks(child, readable)
+		if readable[child.Id] {
+			children = append(children, child)
+			continue
+		}
+
+		n.HiddenForkCount += 1 + child.HiddenForkCount
+		children = append(children, child.Children...)
+	}
+	n.Children = children
+}
+
+func toPresetForkNode(p repository.RoPreset) *PresetForkNode {
+	return &PresetForkNode{
+		Id:            p.Id,
+		PublishName:   p.PublishName,
+		PublisherName: p.UserName,
+		IsPublished:   p.IsPublished,
+		ForkCount:     p.ForkCount,
+		CreatedAt:     p.CreatedAt,
+		Children:      []*PresetForkNode{},
+	}
+}
@@ -0,0 +1,125 @@
+package service
+
+import (
+	"ro-backend/appError"
+	"ro-backend/configuration"
+	"ro-backend/repository"
+)
+
+const normalStartStatPoint = 48
+const rebornStartStatPoint = 100
+const traitPointStartLevel = 200
+const traitPointPerLevel = 3
+
+type StatBudget struct {
+	ClassId          int  `json:"classId"`
+	Level            int  `json:"level"`
+	TotalStatPoint   int  `json:"totalStatPoint"`
+	UsedStatPoint    int  `json:"usedStatPoint"`
+	RemainStatPoint  int  `json:"remainStatPoint"`
+	TotalTraitPoint  int  `json:"totalTraitPoint"`
+	UsedTraitPoint   int  `json:"usedTraitPoint"`
+	RemainTraitPoint int  `json:"remainTraitPoint"`
+	Overspent        bool `json:"overspent"`
+}
+
+// statRaiseCost is the points needed to raise a base stat from value to value+1.
+func statRaiseCost(value int) int {
+	if value < 100 {
+		return (value-1)/10 + 2
+	}
+
+	return 4*((value-100)/5) + 16
+}
+
+// statCost is the points spent to raise a base stat from 1 to value, job bonus is free.
+func statCost(value int) int {
+	total := 0
+	for v := 1; v < value; v++ {
+		total += statRaiseCost(v)
+	}
+
+	return total
+}
+
+// levelUpStatPoint is the points given when the character levels up from level.
+func levelUpStatPoint(level int) int {
+	if level < 100 {
+		return level/5 + 3
+	}
+	if level <= 150 {
+		return level/10 + 13
+	}
+
+	return (level-150)/7 + 28
+}
+
+func totalStatPoint(c RoClass, level int) int {
+	total := normalStartStatPoint
+	if c.Reborn {
+		total = rebornStartStatPoint
+	}
+
+	for lv := 1; lv < level; lv++ {
+		total += levelUpStatPoint(lv)
+	}
+
+	return total
+}
+
+// totalTraitPoint only counts for 4th classes, a trait point costs 1 per value.
+func totalTraitPoint(c RoClass, level int) int {
+	if c.Tier != ClassTierFourth || level <= traitPointStartLevel {
+		return 0
+	}
+
+	return (level - traitPointStartLevel) * traitPointPerLevel
+}
+
+func CalcStatBudget(m repository.PresetModel) StatBudget {
+	class, _ := findRoClass(m.Class)
+
+	used := 0
+	for _, v := range []int{m.Str, m.Agi, m.Vit, m.Int, m.Dex, m.Luk} {
+		used += statCost(v)
+	}
+
+	usedTrait := 0
+	for _, v := range []int{m.Pow, m.Sta, m.Wis, m.Spl, m.Con, m.Crt} {
+		if v > 0 {
+			usedTrait += v
+		}
+	}
+
+	total := totalStatPoint(class, m.Level)
+	totalTrait := totalTraitPoint(class, m.Level)
+
+	return StatBudget{
+		ClassId:          m.Class,
+		Level:            m.Level,
+		TotalStatPoint:   total,
+		UsedStatPoint:    used,
+		RemainStatPoint:  total - used,
+		TotalTraitPoint:  totalTrait,
+		UsedTraitPoint:   usedTrait,
+		RemainTraitPoint: totalTrait - usedTrait,
+		Overspent:        used > total || usedTrait > totalTrait,
+	}
+}
+
+// statBudgetErrors only fails when ro.preset.rejectStatOverspent is set.
+func statBudgetErrors(b StatBudget, prefix string) []appError.FieldError {
+	if !b.Overspent || !configuration.Config.Ro.RejectStatOverspent {
+		return nil
+	}
+
+	errs := []appError.FieldError{}
+	if b.RemainStatPoint < 0 {
+		errs = append(errs, fieldErr(prefix+"level", "uses %v stat points but level %v gives %v", b.UsedStatPoint, b.Level, b.TotalStatPoint))
+	}
+	if b.RemainTraitPoint < 0 {
+		errs = append(errs, fieldErr(prefix+"level", "uses %v trait points but level %v gives %v", b.UsedTraitPoint, b.Level, b.TotalTraitPoint))
+	}
+
+	return errs
+}
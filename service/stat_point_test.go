@@ -0,0 +1,114 @@
+package service
+
+import (
+	"ro-backend/repository"
+	"testing"
+)
+
+func TestStatCost(t *testing.T) {
+	tests := []struct {
+		value int
+		want  int
+	}{
+		{1, 0},
+		{2, 2},
+		{11, 20},
+		{12, 23},
+		// maxing a stat before renewal costs 628 points
+		{99, 628},
+		{100, 639},
+		{105, 719},
+		{130, 1419},
+	}
+
+	for _, tt := range tests {
+		if got := statCost(tt.value); got != tt.want {
+			t.Errorf("statCost(%v) = %v, want %v", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestLevelUpStatPoint(t *testing.T) {
+	tests := []struct {
+		level int
+		want  int
+	}{
+		{1, 3},
+		{4, 3},
+		{5, 4},
+		{98, 22},
+		{99, 22},
+		{100, 23},
+		{150, 28},
+		{151, 28},
+		{157, 29},
+		{199, 35},
+	}
+
+	for _, tt := range tests {
+		if got := levelUpStatPoint(tt.level); got != tt.want {
+			t.Errorf("levelUpStatPoint(%v) = %v, want %v", tt.level, got, tt.want)
+		}
+	}
+}
+
+func TestTotalStatPoint(t *testing.T) {
+	tests := []struct {
+		name    string
+		classId int
+		level   int
+		want    int
+	}{
+		{"new character", 1, 1, 48},
+		{"level 2", 1, 2, 51},
+		{"level 99 normal class", 1, 99, 1273},
+		{"level 99 reborn class", 4008, 99, 1325},
+		{"level 175 third class", 4054, 175, 3278},
+		{"level 175 reborn third class", 4060, 175, 3330},
+	}
+
+	for _, tt := range tests {
+		class, found := findRoClass(tt.classId)
+		if !found {
+			t.Fatalf("%v: class %v not found", tt.name, tt.classId)
+		}
+		if got := totalStatPoint(class, tt.level); got != tt.want {
+			t.Errorf("%v: totalStatPoint = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestTotalTraitPoint(t *testing.T) {
+	tests := []struct {
+		name    string
+		classId int
+		level   int
+		want    int
+	}{
+		{"third class", 4060, 200, 0},
+		{"fourth class at 200", 4252, 200, 0},
+		{"fourth class at 201", 4252, 201, 3},
+		{"fourth class at 275", 4252, 275, 225},
+	}
+
+	for _, tt := range tests {
+		class, _ := findRoClass(tt.classId)
+		if got := totalTraitPoint(class, tt.level); got != tt.want {
+			t.Errorf("%v: totalTraitPoint = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestCalcStatBudget(t *testing.T) {
+	m := repository.PresetModel{Class: 1, Level: 99, Str: 99, Agi: 99, Vit: 1, Int: 1, Dex: 2, Luk: 1}
+
+	got := CalcStatBudget(m)
+	if got.TotalStatPoint != 1273 || got.UsedStatPoint != 1258 || got.RemainStatPoint != 15 || got.Overspent {
+		t.Errorf("CalcStatBudget = %+v, want 1258 of 1273 used", got)
+	}
+
+	m.Dex = 99
+	if got := CalcStatBudget(m); !got.Overspent {
+		t.Errorf("CalcStatBudget = %+v, want overspent", got)
+	}
+}
@@ -2,6 +2,7 @@ package service
 
 import (
 	"fmt"
+	"ro-backend/appError"
 	"ro-backend/repository"
 )
 
@@ -52,3 +53,16 @@ func (s userService) FindUserById(id string) (*repository.User, error) {
 func (s userService) FindUserByEmail(email string) (*repository.User, error) {
 	return s.userRepository.FindUserByEmail(email)
 }
+
+// checkUserActive rejects users that were banned by moderation.
+func checkUserActive(userRepo repository.UserRepository, userId string) error {
+	user, err := userRepo.FindUserById(userId)
+	if err != nil {
+		return err
+	}
+	if user.Status != repository.UserStatus.Active {
+		return fmt.Errorf(appError.ErrUserInactive)
+	}
+
+	return nil
+}
@@ -3,6 +3,7 @@ package main
 import (
 	"context"
 	"fmt"
+	"ro-backend/repository"
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson"
@@ -16,6 +17,17 @@ var refreshTokenCollection *mongo.Collection
 var roPresetCollection *mongo.Collection
 var roPresetForSummaryCollection *mongo.Collection
 var roTagCollection *mongo.Collection
+var presetTagLikeCollection *mongo.Collection
+var roPresetRevisionCollection *mongo.Collection
+var presetQuotaCollection *mongo.Collection
+var itemCollection *mongo.Collection
+var presetFolderCollection *mongo.Collection
+var presetPublishedVersionCollection *mongo.Collection
+var presetTagDefinitionCollection *mongo.Collection
+var presetTrendingScoreCollection *mongo.Collection
+var publisherLeaderboardCollection *mongo.Collection
+var presetReportCollection *mongo.Collection
+var moderationAuditCollection *mongo.Collection
 
 // var storeCollection *mongo.Collection
 // var productCollection *mongo.Collection
@@ -76,11 +88,150 @@ func connectMongoDB() (err error) {
 				"user_id": 1,
 			},
 		},
+		{
+			Keys: bson.M{
+				"root_preset_id": 1,
+			},
+		},
+		{
+			Keys: bson.M{
+				"forked_from_user_id": 1,
+			},
+		},
+		{
+			Keys: bson.D{
+				{Key: "user_id", Value: 1},
+				{Key: "folder_id", Value: 1},
+			},
+		},
+		{
+			Keys: bson.D{
+				{Key: "user_id", Value: 1},
+				{Key: "updated_at", Value: -1},
+				{Key: "id", Value: -1},
+			},
+		},
+		{
+			Keys: bson.D{
+				{Key: "user_id", Value: 1},
+				{Key: "created_at", Value: -1},
+				{Key: "id", Value: -1},
+			},
+		},
+		{
+			Keys: bson.D{
+				{Key: "published.equipped_items.item_id", Value: 1},
+				{Key: "published.equipped_items.slot", Value: 1},
+				{Key: "is_published", Value: 1},
+				{Key: "published.class_id", Value: 1},
+			},
+		},
+		{
+			Keys: bson.D{
+				{Key: "published.item_options.option", Value: 1},
+				{Key: "published.item_options.slot", Value: 1},
+				{Key: "is_published", Value: 1},
+				{Key: "published.class_id", Value: 1},
+			},
+		},
+		{
+			// the likes sort of published preset searches
+			Keys: bson.D{
+				{Key: "total_like", Value: -1},
+				{Key: "published_at", Value: -1},
+			},
+		},
+		{
+			// only presets in the trash have deleted_at, for the hourly purge
+			Keys: bson.M{
+				"deleted_at": 1,
+			},
+			Options: options.Index().SetSparse(true),
+		},
 	})
 	if err != nil {
 		panic(fmt.Errorf("index ro_presets: %w", err))
 	}
 
+	roPresetRevisionCollection = mongoDb.Collection("ro_preset_revisions")
+	_, err = roPresetRevisionCollection.Indexes().CreateMany(context.Background(), []mongo.IndexModel{
+		{
+			Keys: bson.D{
+				{Key: "preset_id", Value: 1},
+				{Key: "revision", Value: 1},
+			},
+			Options: options.Index().SetUnique(true),
+		},
+	})
+	if err != nil {
+		panic(fmt.Errorf("index ro_preset_revisions: %w", err))
+	}
+
+	presetPublishedVersionCollection = mongoDb.Collection("preset_published_versions")
+	_, err = presetPublishedVersionCollection.Indexes().CreateMany(context.Background(), []mongo.IndexModel{
+		{
+			Keys: bson.D{
+				{Key: "preset_id", Value: 1},
+				{Key: "version", Value: 1},
+			},
+			Options: options.Index().SetUnique(true),
+		},
+	})
+	if err != nil {
+		panic(fmt.Errorf("index preset_published_versions: %w", err))
+	}
+
+	presetQuotaCollection = mongoDb.Collection("preset_quotas")
+	_, err = presetQuotaCollection.Indexes().CreateMany(context.Background(), []mongo.IndexModel{
+		{
+			Keys: bson.M{
+				"user_id": 1,
+			},
+			Options: options.Index().SetUnique(true),
+		},
+	})
+	if err != nil {
+		panic(fmt.Errorf("index preset_quotas: %w", err))
+	}
+
+	itemCollection = mongoDb.Collection("items")
+	_, err = itemCollection.Indexes().CreateMany(context.Background(), []mongo.IndexModel{
+		{
+			Keys: bson.M{
+				"id": 1,
+			},
+			Options: options.Index().SetUnique(true),
+		},
+		{
+			Keys: bson.D{
+				{Key: "type", Value: 1},
+				{Key: "positions", Value: 1},
+			},
+		},
+	})
+	if err != nil {
+		panic(fmt.Errorf("index items: %w", err))
+	}
+
+	presetFolderCollection = mongoDb.Collection("preset_folders")
+	_, err = presetFolderCollection.Indexes().CreateMany(context.Background(), []mongo.IndexModel{
+		{
+			Keys: bson.M{
+				"id": 1,
+			},
+			Options: options.Index().SetUnique(true),
+		},
+		{
+			Keys: bson.D{
+				{Key: "user_id", Value: 1},
+				{Key: "order", Value: 1},
+			},
+		},
+	})
+	if err != nil {
+		panic(fmt.Errorf("index preset_folders: %w", err))
+	}
+
 	roTagCollection = mongoDb.Collection("preset_tags")
 	_, err = roTagCollection.Indexes().CreateMany(context.Background(), []mongo.IndexModel{
 		{
@@ -112,6 +263,163 @@ func connectMongoDB() (err error) {
 		panic(fmt.Errorf("index preset_tags: %w", err))
 	}
 
+	presetTagLikeCollection = mongoDb.Collection("preset_tag_likes")
+	_, err = presetTagLikeCollection.Indexes().CreateMany(context.Background(), []mongo.IndexModel{
+		{
+			Keys: bson.D{
+				{Key: "tag_id", Value: 1},
+				{Key: "user_id", Value: 1},
+			},
+			Options: options.Index().SetUnique(true),
+		},
+		{
+			Keys: bson.D{
+				{Key: "user_id", Value: 1},
+				{Key: "created_at", Value: -1},
+			},
+		},
+		{
+			Keys: bson.M{
+				"created_at": 1,
+			},
+		},
+	})
+	if err != nil {
+		panic(fmt.Errorf("index preset_tag_likes: %w", err))
+	}
+
+	presetTagDefinitionCollection = mongoDb.Collection("preset_tag_definitions")
+	_, err = presetTagDefinitionCollection.Indexes().CreateMany(context.Background(), []mongo.IndexModel{
+		{
+			Keys: bson.M{
+				"slug": 1,
+			},
+			Options: options.Index().SetUnique(true),
+		},
+	})
+	if err != nil {
+		panic(fmt.Errorf("index preset_tag_definitions: %w", err))
+	}
+
+	presetTrendingScoreCollection = mongoDb.Collection("preset_trending_scores")
+	trendingScoreIndexes := []mongo.IndexModel{
+		{
+			Keys: bson.D{
+				{Key: "window", Value: 1},
+				{Key: "kind", Value: 1},
+				{Key: "class_id", Value: 1},
+				{Key: "score", Value: -1},
+			},
+		},
+		{
+			// scores are upserted by this key, see ReplaceScores
+			Keys: bson.D{
+				{Key: "kind", Value: 1},
+				{Key: "key", Value: 1},
+				{Key: "class_id", Value: 1},
+				{Key: "window", Value: 1},
+			},
+			Options: options.Index().SetUnique(true),
+		},
+	}
+	_, err = presetTrendingScoreCollection.Indexes().CreateMany(context.Background(), trendingScoreIndexes)
+	if mongo.IsDuplicateKeyError(err) {
+		// scores written before the unique index may be duplicated, they are recomputed on the next refresh
+		_, err = presetTrendingScoreCollection.DeleteMany(context.Background(), bson.M{})
+		if err == nil {
+			_, err = presetTrendingScoreCollection.Indexes().CreateMany(context.Background(), trendingScoreIndexes)
+		}
+	}
+	if err != nil {
+		panic(fmt.Errorf("index preset_trending_scores: %w", err))
+	}
+
+	publisherLeaderboardCollection = mongoDb.Collection("publisher_leaderboard")
+	_, err = publisherLeaderboardCollection.Indexes().CreateMany(context.Background(), []mongo.IndexModel{
+		{
+			// rows are upserted by this key, see ReplaceLeaderboard
+			Keys: bson.D{
+				{Key: "window", Value: 1},
+				{Key: "class_id", Value: 1},
+				{Key: "user_id", Value: 1},
+			},
+			Options: options.Index().SetUnique(true),
+		},
+		{
+			Keys: bson.D{
+				{Key: "window", Value: 1},
+				{Key: "class_id", Value: 1},
+				{Key: "total_like", Value: -1},
+				{Key: "total_fork", Value: -1},
+			},
+		},
+		{
+			Keys: bson.D{
+				{Key: "window", Value: 1},
+				{Key: "class_id", Value: 1},
+				{Key: "total_fork", Value: -1},
+				{Key: "total_like", Value: -1},
+			},
+		},
+		{
+			Keys: bson.D{
+				{Key: "window", Value: 1},
+				{Key: "class_id", Value: 1},
+				{Key: "total_published", Value: -1},
+				{Key: "total_like", Value: -1},
+			},
+		},
+	})
+	if err != nil {
+		panic(fmt.Errorf("index publisher_leaderboard: %w", err))
+	}
+
+	presetReportCollection = mongoDb.Collection("preset_reports")
+	_, err = presetReportCollection.Indexes().CreateMany(context.Background(), []mongo.IndexModel{
+		{
+			Keys: bson.M{
+				"id": 1,
+			},
+			Options: options.Index().SetUnique(true),
+		},
+		{
+			// one open report per reporter and target, a closed target can be reported again
+			Keys: bson.D{
+				{Key: "target_type", Value: 1},
+				{Key: "target_id", Value: 1},
+				{Key: "reporter_id", Value: 1},
+			},
+			Options: options.Index().SetUnique(true).SetPartialFilterExpression(bson.M{"status": repository.ReportStatuses.Open}),
+		},
+		{
+			Keys: bson.D{
+				{Key: "status", Value: 1},
+				{Key: "created_at", Value: 1},
+			},
+		},
+	})
+	if err != nil {
+		panic(fmt.Errorf("index preset_reports: %w", err))
+	}
+
+	moderationAuditCollection = mongoDb.Collection("moderation_audit_logs")
+	_, err = moderationAuditCollection.Indexes().CreateMany(context.Background(), []mongo.IndexModel{
+		{
+			Keys: bson.M{
+				"created_at": -1,
+			},
+		},
+		{
+			Keys: bson.D{
+				{Key: "publisher_id", Value: 1},
+				{Key: "created_at", Value: -1},
+			},
+		},
+	})
+	if err != nil {
+		panic(fmt.Errorf("index moderation_audit_logs: %w", err))
+	}
+
 	// storeCollection = mongoDb.Collection("store")
 	// _, err = storeCollection.Indexes().CreateMany(context.Background(), []mongo.IndexModel{
 	// 	{